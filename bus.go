@@ -0,0 +1,150 @@
+package joysticks
+
+import (
+	"time"
+)
+
+// Bus routes the events of several HIDs, from one go routine, so that events from all of them can be put onto shared channels in timestamp order.
+// events are tagged, through their Source.Device, with the index of the HID they came from.
+// Note: timestamp order relies on the devices sharing a time base, as devices from the Linux driver do.
+type Bus struct {
+	HIDs  []*HID
+	Delay time.Duration // events are held this long, so later arriving events, from other devices, with earlier timestamps, can go first, (ConnectBus sets 10ms.)
+}
+
+// ConnectBus connects to the HIDs with the indexes provided, skipping any not available, returning nil if none are.
+func ConnectBus(indexes ...int) *Bus {
+	b := &Bus{Delay: time.Millisecond * 10}
+	for _, i := range indexes {
+		if d := Connect(i); d != nil {
+			b.HIDs = append(b.HIDs, d)
+		}
+	}
+	if len(b.HIDs) == 0 {
+		return nil
+	}
+	return b
+}
+
+// Device returns the HID connected with the index provided, or nil, its On<xxx> methods register events scoped to only that device.
+func (b *Bus) Device(index int) *HID {
+	for _, d := range b.HIDs {
+		if d.Index == index {
+			return d
+		}
+	}
+	return nil
+}
+
+// On registers the event, of the On<xxx> method provided, for the index provided, on all the bus's HIDs, returning the one chan they all put events onto.
+// ( as with a HID, re-registering, stops events going on the old channel.)
+func (b *Bus) On(method func(HID, uint8, ...Option) chan Event, index uint8, opts ...Option) chan Event {
+	if len(b.HIDs) == 0 {
+		return nil
+	}
+	c := method(*b.HIDs[0], index, opts...)
+	for _, d := range b.HIDs[1:] {
+		d.rebind(method(*d, index, opts...), c)
+	}
+	return c
+}
+
+// ParcelOutEvents waits on all the bus's HIDs OSEvents channels (so is blocking), then, in timestamp order, puts any events matching onto any registered channel(s).
+// returns when all the HIDs OSEvents channels are closed, and any events held have been parcelled out.
+func (b *Bus) ParcelOutEvents() {
+	type arrival struct {
+		osEventRecord
+		*HID
+		at time.Time
+	}
+	in := make(chan arrival)
+	deferred := make(chan func())
+	finished := make(chan struct{})
+	for _, d := range b.HIDs {
+		go func(d *HID) {
+			events := d.OSEvents
+			for {
+				select {
+				case evt, ok := <-events:
+					if !ok {
+						in <- arrival{} // no HID, so signals this one has finished
+						events = nil    // but its timers still need running, until the bus finishes.
+						continue
+					}
+					in <- arrival{evt, d, time.Now()}
+				case f := <-d.deferred:
+					select {
+					case deferred <- f:
+					case <-finished:
+						return
+					}
+				case <-finished:
+					return
+				}
+			}
+		}(d)
+	}
+	defer func() {
+		close(finished)
+		for _, d := range b.HIDs {
+			close(d.done)
+		}
+	}()
+	var pending []arrival // in timestamp order
+	timer := time.NewTimer(b.Delay)
+	timer.Stop()
+	for open := len(b.HIDs); open > 0 || len(pending) > 0; {
+		select {
+		case a := <-in:
+			if a.HID == nil {
+				open--
+				if open == 0 {
+					timer.Reset(0) // flush
+				}
+				continue
+			}
+			i := len(pending)
+			for i > 0 && pending[i-1].Time > a.Time {
+				i--
+			}
+			pending = append(pending, arrival{})
+			copy(pending[i+1:], pending[i:])
+			pending[i] = a
+			if len(pending) == 1 {
+				timer.Reset(b.Delay)
+			}
+		case f := <-deferred:
+			f()
+		case now := <-timer.C:
+			// everything with a timestamp before the last one that has been held long enough can go.
+			n := 0
+			for i, a := range pending {
+				if open == 0 || !now.Before(a.at.Add(b.Delay)) {
+					n = i + 1
+				}
+			}
+			for _, a := range pending[:n] {
+				a.HID.route(a.osEventRecord)
+			}
+			pending = pending[n:]
+			if len(pending) > 0 {
+				oldest := pending[0].at
+				for _, a := range pending {
+					if a.at.Before(oldest) {
+						oldest = a.at
+					}
+				}
+				timer.Reset(time.Until(oldest.Add(b.Delay)))
+			}
+		}
+	}
+}
+
+// make events that are going onto a channel go onto another.
+func (d HID) rebind(from, to chan Event) {
+	for s, c := range d.Events {
+		if c == from {
+			d.Events[s] = to
+		}
+	}
+}
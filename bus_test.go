@@ -0,0 +1,55 @@
+package joysticks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBusOrder(t *testing.T) {
+	d1, d2 := newHID(1), newHID(2)
+	d1.Buttons[0] = button{number: 1}
+	d2.Buttons[0] = button{number: 1}
+	b := &Bus{HIDs: []*HID{d1, d2}, Delay: time.Millisecond * 50}
+	all := b.On(HID.OnClose, 1)
+	only2 := b.Device(2).OnOpen(1)
+	// all queued before routing starts, so they arrive within the delay, however slowly scheduled.
+	d1.OSEvents, d2.OSEvents = make(chan osEventRecord, 2), make(chan osEventRecord, 2)
+	d1.OSEvents <- osEventRecord{Time: 30, Value: 1, Type: 1}
+	d2.OSEvents <- osEventRecord{Time: 10, Value: 1, Type: 1}
+	d1.OSEvents <- osEventRecord{Time: 20, Value: 1, Type: 1}
+	d2.OSEvents <- osEventRecord{Time: 40, Value: 0, Type: 1}
+	close(d1.OSEvents)
+	close(d2.OSEvents)
+	go b.ParcelOutEvents()
+	for _, w := range []struct {
+		device int
+		moment time.Duration
+	}{{2, 10}, {1, 20}, {1, 30}} {
+		e := (<-all).(ButtonEvent)
+		if e.Device != w.device || e.Moment() != w.moment*time.Millisecond || e.Kind() != ButtonClose {
+			t.Errorf("expected device %d at %v, got %+v", w.device, w.moment*time.Millisecond, e)
+		}
+	}
+	if e := (<-only2).(ButtonEvent); e.Device != 2 || e.Closed {
+		t.Errorf("expected device 2 open, got %+v", e)
+	}
+}
+
+func TestBusTimersAfterClose(t *testing.T) {
+	d1, d2 := newHID(1), newHID(2)
+	d1.Buttons[0] = button{number: 1}
+	b := &Bus{HIDs: []*HID{d1, d2}, Delay: time.Millisecond * 10}
+	held := b.Device(1).OnHold(1, LongPressAfter(time.Millisecond*50))
+	go b.ParcelOutEvents()
+	d1.OSEvents <- osEventRecord{Value: 1, Type: 1}
+	close(d1.OSEvents)
+	select {
+	case e := <-held:
+		if origin(e).Device != 1 {
+			t.Errorf("expected device 1 held, got %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected hold, from a device closed while the bus still runs")
+	}
+	close(d2.OSEvents)
+}
@@ -0,0 +1,167 @@
+package joysticks
+
+import (
+	"time"
+)
+
+// Opposing is how a button axis resolves both its buttons being closed.
+type Opposing uint8
+
+const (
+	CancelOpposing Opposing = iota // centred
+	LastPressWins
+	FirstPressWins
+)
+
+// a hat axis driven by a pair of buttons.
+type buttonAxis struct {
+	index              uint8 // of the hat axis
+	negative, positive uint8 // button numbers
+	negAt, posAt       time.Duration
+	neg, pos           bool
+	value              float32
+	at                 time.Duration // moment of value
+	ramping            bool
+	settings
+}
+
+// ButtonAxis makes an axis (1 or 2) of a virtual hat, with the number provided, from the buttons with the negative and positive numbers, so buttons can be used with all the hat events.
+// closing a button moves the axis to its end, opening it back to the centre, immediately, or with the RampUp and RampDown options, at a rate.
+// how both being closed is resolved is set by the OpposingPresses option, by default the axis is centred.
+// the virtual hat gets both axes, the other one stays centred unless also made from buttons, and returns false, adding nothing, if the hat exists, and isn't virtual, or the axis is already made.
+func (d HID) ButtonAxis(hat, axis, negative, positive uint8, opts ...Option) bool {
+	if axis != 1 && axis != 2 {
+		return false
+	}
+	index, ok := d.virtualHat(hat)
+	if !ok {
+		return false
+	}
+	index += axis - 1
+	if _, ok := d.buttonAxes[index]; ok {
+		return false
+	}
+	d.buttonAxes[index] = &buttonAxis{index: index, negative: negative, positive: positive, settings: newSettings(d.Timing, append(append([]Option{}, d.opts...), opts...))}
+	return true
+}
+
+// index of the X axis of a virtual hat, adding it at the highest free pair of indexes, if it doesn't exist, false if the hat exists but isn't virtual.
+func (d HID) virtualHat(hat uint8) (uint8, bool) {
+	if d.HatExists(hat) {
+		for i, h := range d.HatAxes {
+			if h.number != hat || h.axis != 1 {
+				continue
+			}
+			if _, ok := d.buttonAxes[i]; ok {
+				return i, true
+			}
+			if _, ok := d.buttonAxes[i+1]; ok {
+				return i, true
+			}
+		}
+		return 0, false
+	}
+	for i := 254; i >= 0; i-- {
+		_, xUsed := d.HatAxes[uint8(i)]
+		_, yUsed := d.HatAxes[uint8(i+1)]
+		if !xUsed && !yUsed {
+			d.HatAxes[uint8(i)] = hatAxis{number: hat, axis: 1}
+			d.HatAxes[uint8(i+1)] = hatAxis{number: hat, axis: 2}
+			return uint8(i), true
+		}
+	}
+	return 0, false
+}
+
+// where a button axis is heading, from its buttons.
+func (a *buttonAxis) target() float32 {
+	switch {
+	case a.pos && a.neg:
+		switch a.opposing {
+		case LastPressWins:
+			if a.posAt >= a.negAt {
+				return 1
+			}
+			return -1
+		case FirstPressWins:
+			if a.posAt < a.negAt {
+				return 1
+			}
+			return -1
+		}
+		return 0
+	case a.pos:
+		return 1
+	case a.neg:
+		return -1
+	}
+	return 0
+}
+
+// move a button axis's value toward its target, for a time, at its ramp rates, stopping at the centre when passing it, unless ramping down is immediate.
+func (a *buttonAxis) step(dt time.Duration) {
+	target := a.target()
+	if a.value*target < 0 || target == 0 {
+		// toward the centre
+		if a.rampDown > 0 {
+			a.value = approach(a.value, 0, a.rampDown*float32(dt.Seconds()))
+			return
+		}
+		a.value = 0
+		if target == 0 {
+			return
+		}
+	}
+	if a.rampUp <= 0 {
+		a.value = target
+		return
+	}
+	a.value = approach(a.value, target, a.rampUp*float32(dt.Seconds()))
+}
+
+// move v toward target, by an amount, without passing it, (reaching it if within rounding.)
+func approach(v, target, by float32) float32 {
+	if v < target {
+		if v += by; v > target-1e-6 {
+			return target
+		}
+		return v
+	}
+	if v -= by; v < target+1e-6 {
+		return target
+	}
+	return v
+}
+
+// update the button axes that use a button that has changed.
+func (d HID) buttonAxisInput(number uint8, t time.Duration, closed bool) {
+	for _, a := range d.buttonAxes {
+		switch number {
+		case a.negative:
+			a.neg, a.negAt = closed, t
+		case a.positive:
+			a.pos, a.posAt = closed, t
+		default:
+			continue
+		}
+		if a.ramping {
+			continue // the next step heads for the new target
+		}
+		a.at = t
+		d.buttonAxisStep(a, 0)
+	}
+}
+
+// move a button axis, sending it as input to its hat axis, and, if it hasn't reached its target, carry on after its ramp step.
+func (d HID) buttonAxisStep(a *buttonAxis, dt time.Duration) {
+	a.at += dt
+	v := a.value
+	a.step(dt)
+	if a.value != v {
+		d.hatInput(a.index, a.at, int16(a.value*maxValue))
+	}
+	a.ramping = a.value != a.target()
+	if a.ramping {
+		d.after(a.rampStep, func() { d.buttonAxisStep(a, a.rampStep) })
+	}
+}
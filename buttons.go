@@ -0,0 +1,203 @@
+package joysticks
+
+import (
+	"time"
+)
+
+// button held event type, Elapsed since it closed.
+type HoldEvent struct {
+	when
+	Source
+	Elapsed time.Duration
+}
+
+// button tapped event type, Count of taps so far, or for kind ButtonTaps, in the whole sequence.
+type TapEvent struct {
+	when
+	Source
+	Count uint
+}
+
+// button still closed, the LongPress Timing after closing, event channel.
+// (unlike OnLong, the event is sent while the button is held, from a timer, so doesn't depend on event timestamps.)
+// with the HoldProgressEvery option, also receives HoldEvent's, of kind ButtonHoldProgress, at that interval, for as long as the button stays closed.
+func (d HID) OnHold(index uint8, opts ...Option) chan Event {
+	return d.register(ButtonHold, index, opts)
+}
+
+// a button has changed, route it through debouncing, layers, then any chords, before sending its own events.
+func (d HID) buttonInput(index uint8, t time.Duration, raw int16) {
+	b := d.Buttons[index]
+	if b.settle > 0 {
+		b.latest = heldButton{index, t, raw}
+		d.Buttons[index] = b
+		if b.settling {
+			return
+		}
+		d.startSettling(index, raw)
+	}
+	d.buttonSettled(index, t, raw)
+}
+
+// a button has changed, after any debouncing, so switch any layers, then route it through any chords.
+// a layer's suppressed button still has its state updated, just no events sent.
+func (d HID) buttonSettled(index uint8, t time.Duration, raw int16) {
+	if d.layered(index, raw) {
+		b := d.Buttons[index]
+		b.time, b.value = t, raw != 0
+		d.Buttons[index] = b
+		return
+	}
+	if d.chorded(index, t, raw) {
+		return
+	}
+	d.buttonChanged(index, t, raw)
+}
+
+// Debounce ignores changes to a button for the settle duration after any change it passes on, then, if the button has ended up changed, passes that on.
+// so the first change is immediate, and bouncing, shorter than settle, is ignored. a settle of zero stops debouncing.
+// not thread safe, with ParcelOutEvents, so use before it runs.
+func (d HID) Debounce(number uint8, settle time.Duration) {
+	for i, b := range d.Buttons {
+		if b.number == number {
+			b.settle = settle
+			d.Buttons[i] = b
+		}
+	}
+}
+
+// ignore changes to a button for its settle time, then pass on its latest change, if it ends up different.
+func (d HID) startSettling(index uint8, raw int16) {
+	b := d.Buttons[index]
+	b.settling, b.passed = true, raw
+	d.Buttons[index] = b
+	d.after(b.settle, func() {
+		c := d.Buttons[index]
+		c.settling = false
+		d.Buttons[index] = c
+		if c.latest.raw != c.passed {
+			d.startSettling(index, c.latest.raw)
+			d.buttonSettled(index, c.latest.time, c.latest.raw)
+		}
+	})
+}
+
+// run f, on the routing go routine, after a delay, unless routing has finished by then.
+func (d HID) after(delay time.Duration, f func()) {
+	time.AfterFunc(delay, func() {
+		select {
+		case d.deferred <- f:
+		case <-d.done:
+		}
+	})
+}
+
+// set timers for the hold events of a button that has just closed.
+func (d HID) startHold(index uint8, b button) {
+	_, s, ok := d.channel(ButtonHold, b.number, b.layer)
+	if !ok {
+		return
+	}
+	// still closed from the same press.
+	held := func() bool {
+		c := d.Buttons[index]
+		return c.value && c.presses == b.presses
+	}
+	send := func(k Kind, elapsed time.Duration) {
+		if c, _, ok := d.channel(ButtonHold, b.number, b.layer); ok {
+			c <- HoldEvent{when{b.time + elapsed}, Source{k, d.Index, b.number, 0, 1, 1}, elapsed}
+		}
+	}
+	d.after(s.LongPress, func() {
+		if held() {
+			send(ButtonHold, s.LongPress)
+		}
+	})
+	if s.holdProgress <= 0 {
+		return
+	}
+	var progress func(elapsed time.Duration)
+	progress = func(elapsed time.Duration) {
+		if !held() {
+			return
+		}
+		send(ButtonHoldProgress, elapsed)
+		d.after(s.holdProgress, func() { progress(elapsed + s.holdProgress) })
+	}
+	d.after(s.holdProgress, func() { progress(s.holdProgress) })
+}
+
+// button taps event channel.
+// a sequence of taps continues while the button re-closes within the DoublePress Timing of opening.
+// receives TapEvent's, of kind ButtonTap, as each tap closes, and one of kind ButtonTaps, with the final count, once the sequence ends.
+// with the SuppressTaps option, only the ButtonTaps event is sent, so actions for different counts don't all fire.
+func (d HID) OnTap(index uint8, opts ...Option) chan Event {
+	return d.register(ButtonTap, index, opts)
+}
+
+// count the taps of a button that has just changed, sending tap events, and setting a timer for the end of the sequence.
+func (d HID) tapped(index uint8, b button) {
+	_, s, ok := d.channel(ButtonTap, b.number, b.layer)
+	if !ok {
+		return
+	}
+	send := func(k Kind, m time.Duration, count uint) {
+		if c, _, ok := d.channel(ButtonTap, b.number, b.layer); ok {
+			c <- TapEvent{when{m}, Source{k, d.Index, b.number, 0, 1, 1}, count}
+		}
+	}
+	if b.value {
+		b.taps++
+		d.Buttons[index] = b
+		if !s.suppressTaps {
+			send(ButtonTap, b.time, b.taps)
+		}
+		return
+	}
+	d.after(s.DoublePress, func() {
+		c := d.Buttons[index]
+		if c.presses != b.presses {
+			return // re-closed, so the sequence continues
+		}
+		c.taps = 0
+		d.Buttons[index] = c
+		send(ButtonTaps, b.time+s.DoublePress, b.taps)
+	})
+}
+
+// button latched state change event channel.
+// each close of the button toggles the latched state, the event's Closed, readable at any time using ButtonLatched.
+// with the ToggleOnHold option, the button has to be held closed for the LongPress Timing to toggle.
+func (d HID) OnToggle(index uint8, opts ...Option) chan Event {
+	return d.register(ButtonToggle, index, opts)
+}
+
+// Button latched state.
+func (d HID) ButtonLatched(index uint8) bool {
+	return d.Buttons[index].latched
+}
+
+// toggle the latched state of a button that has just changed.
+func (d HID) toggled(index uint8, b button) {
+	_, s, ok := d.channel(ButtonToggle, b.number, b.layer)
+	if !ok || !b.value {
+		return
+	}
+	toggle := func(m time.Duration) {
+		c := d.Buttons[index]
+		c.latched = !c.latched
+		d.Buttons[index] = c
+		if e, _, ok := d.channel(ButtonToggle, b.number, b.layer); ok {
+			e <- ButtonEvent{when{m}, Source{ButtonToggle, d.Index, b.number, 0, 1, 1}, c.latched}
+		}
+	}
+	if !s.toggleOnHold {
+		toggle(b.time)
+		return
+	}
+	d.after(s.LongPress, func() {
+		if c := d.Buttons[index]; c.value && c.presses == b.presses {
+			toggle(b.time + s.LongPress)
+		}
+	})
+}
@@ -0,0 +1,346 @@
+package joysticks
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// a HID, without a device, with buttons numbered 1...buttons and hats numbered 1...hats, in the order the driver reports them.
+func testHID(buttons, hats int) *HID {
+	d := newHID(1)
+	for i := 0; i < buttons; i++ {
+		d.Buttons[uint8(i)] = button{number: uint8(i + 1)}
+	}
+	for i := 0; i < hats*2; i++ {
+		d.HatAxes[uint8(i)] = hatAxis{number: uint8(i/2 + 1), axis: uint8(i%2 + 1)}
+	}
+	return d
+}
+
+// expect no event on a channel for a while.
+func expectNone(t *testing.T, c chan Event, wait time.Duration) {
+	select {
+	case e := <-c:
+		t.Errorf("unexpected event %+v", e)
+	case <-time.After(wait):
+	}
+}
+
+func TestHold(t *testing.T) {
+	d := testHID(1, 0)
+	held := d.OnHold(1, LongPressAfter(time.Millisecond*40), HoldProgressEvery(time.Millisecond*30))
+	go d.ParcelOutEvents()
+	// synthetic events, so no timestamps
+	d.InsertSyntheticEvent(1, 1, 0)
+	d.InsertSyntheticEvent(0, 1, 0)
+	expectNone(t, held, time.Millisecond*60)
+
+	d.InsertSyntheticEvent(1, 1, 0)
+	for _, w := range []struct {
+		Kind
+		elapsed time.Duration
+	}{{ButtonHoldProgress, 30}, {ButtonHold, 40}, {ButtonHoldProgress, 60}} {
+		e := (<-held).(HoldEvent)
+		if e.Kind() != w.Kind || e.Elapsed != w.elapsed*time.Millisecond || e.Moment() != e.Elapsed {
+			t.Errorf("expected %v after %v, got %+v", w.Kind, w.elapsed*time.Millisecond, e)
+		}
+	}
+	d.InsertSyntheticEvent(0, 1, 0)
+	expectNone(t, held, time.Millisecond*60)
+}
+
+func TestTaps(t *testing.T) {
+	d := testHID(2, 0)
+	taps := d.OnTap(1, DoublePressWithin(time.Millisecond*40))
+	resolved := d.OnTap(2, DoublePressWithin(time.Millisecond*40), SuppressTaps())
+	go d.ParcelOutEvents()
+	go func() {
+		for i := 0; i < 2; i++ {
+			d.InsertSyntheticEvent(1, 1, 0)
+			d.InsertSyntheticEvent(0, 1, 0)
+		}
+	}()
+	for _, w := range []struct {
+		Kind
+		count uint
+	}{{ButtonTap, 1}, {ButtonTap, 2}, {ButtonTaps, 2}} {
+		if e := (<-taps).(TapEvent); e.Kind() != w.Kind || e.Count != w.count {
+			t.Errorf("expected %v count %d, got %+v", w.Kind, w.count, e)
+		}
+	}
+	go func() {
+		for i := 0; i < 3; i++ {
+			d.InsertSyntheticEvent(1, 1, 1)
+			d.InsertSyntheticEvent(0, 1, 1)
+		}
+	}()
+	if e := (<-resolved).(TapEvent); e.Kind() != ButtonTaps || e.Count != 3 {
+		t.Errorf("expected only a sequence of 3 taps, got %+v", e)
+	}
+	expectNone(t, resolved, time.Millisecond*60)
+
+	// a second sequence, after the first has ended, counts from one.
+	d.InsertSyntheticEvent(1, 1, 1)
+	d.InsertSyntheticEvent(0, 1, 1)
+	if e := (<-resolved).(TapEvent); e.Count != 1 {
+		t.Errorf("expected a single tap, got %+v", e)
+	}
+}
+
+func TestChord(t *testing.T) {
+	d := testHID(3, 0)
+	chord := d.With(ChordWithin(time.Millisecond*40), Suppress()).OnChord(1, 2)
+	close1, close2, open1 := d.OnClose(1), d.OnClose(2), d.OnOpen(1)
+	go d.ParcelOutEvents()
+
+	d.InsertSyntheticEvent(1, 1, 0)
+	d.InsertSyntheticEvent(1, 1, 1)
+	if e := (<-chord).(ButtonEvent); !e.Closed || e.Number != 1 || e.Kind() != ButtonChord {
+		t.Errorf("expected chord closed, got %+v", e)
+	}
+	d.InsertSyntheticEvent(0, 1, 1)
+	if e := (<-chord).(ButtonEvent); e.Closed {
+		t.Errorf("expected chord open, got %+v", e)
+	}
+	d.InsertSyntheticEvent(0, 1, 0)
+	expectNone(t, close1, time.Millisecond*60)
+	expectNone(t, close2, 0)
+	expectNone(t, open1, 0)
+
+	// on its own, a button's events are only held back.
+	d.InsertSyntheticEvent(1, 1, 0)
+	go d.InsertSyntheticEvent(0, 1, 0)
+	<-close1
+	<-open1
+	d.InsertSyntheticEvent(1, 1, 1)
+	select {
+	case <-close2:
+	case <-time.After(time.Millisecond * 200):
+		t.Error("expected held close, after the chord window")
+	}
+	d.InsertSyntheticEvent(0, 1, 1)
+	expectNone(t, chord, time.Millisecond*60)
+}
+
+func TestLayeredChord(t *testing.T) {
+	d := testHID(3, 0)
+	d.Layer(1, 1, Suppress())
+	chord := d.With(OnLayer(1), ChordWithin(time.Millisecond*40)).OnChord(2, 3)
+	go d.ParcelOutEvents()
+
+	d.InsertSyntheticEvent(1, 1, 1)
+	d.InsertSyntheticEvent(1, 1, 2)
+	expectNone(t, chord, time.Millisecond*20)
+	d.InsertSyntheticEvent(0, 1, 1)
+	d.InsertSyntheticEvent(0, 1, 2)
+
+	d.InsertSyntheticEvent(1, 1, 0)
+	d.InsertSyntheticEvent(1, 1, 1)
+	go d.InsertSyntheticEvent(1, 1, 2)
+	if e := (<-chord).(ButtonEvent); !e.Closed || e.Kind() != ButtonChord {
+		t.Errorf("expected chord closed, on the layer, got %+v", e)
+	}
+}
+
+func TestToggle(t *testing.T) {
+	d := testHID(2, 0)
+	tap := d.OnToggle(1)
+	hold := d.OnToggle(2, ToggleOnHold(), LongPressAfter(time.Millisecond*40))
+	done := make(chan bool)
+	go func() {
+		d.ParcelOutEvents()
+		close(done)
+	}()
+	for _, latched := range []bool{true, false, true} {
+		d.InsertSyntheticEvent(1, 1, 0)
+		if e := (<-tap).(ButtonEvent); e.Closed != latched || e.Kind() != ButtonToggle {
+			t.Errorf("expected latched %v, got %+v", latched, e)
+		}
+		d.InsertSyntheticEvent(0, 1, 0)
+	}
+	d.InsertSyntheticEvent(1, 1, 1)
+	d.InsertSyntheticEvent(0, 1, 1)
+	expectNone(t, hold, time.Millisecond*60)
+	d.InsertSyntheticEvent(1, 1, 1)
+	if e := (<-hold).(ButtonEvent); !e.Closed {
+		t.Errorf("expected latched, got %+v", e)
+	}
+	d.InsertSyntheticEvent(0, 1, 1)
+	close(d.OSEvents)
+	<-done
+	if !d.ButtonLatched(0) || !d.ButtonLatched(1) {
+		t.Error("expected buttons latched")
+	}
+}
+
+func TestDebounce(t *testing.T) {
+	d := testHID(2, 0)
+	d.Debounce(1, time.Millisecond*30)
+	changes := d.OnButton(1)
+	go d.ParcelOutEvents()
+	bounce := func(vs ...int16) {
+		for _, v := range vs {
+			d.OSEvents <- osEventRecord{Value: v, Type: 1, Index: 0}
+		}
+	}
+	expect := func(closed bool) {
+		select {
+		case e := <-changes:
+			if e.(ButtonEvent).Closed != closed {
+				t.Errorf("expected closed %v, got %+v", closed, e)
+			}
+		case <-time.After(time.Millisecond * 100):
+			t.Errorf("expected closed %v", closed)
+		}
+	}
+	go bounce(1, 0, 1, 0, 1)
+	expect(true)
+	expectNone(t, changes, time.Millisecond*60)
+	go bounce(0, 1, 0)
+	expect(false)
+	expectNone(t, changes, time.Millisecond*60)
+
+	// bounces ending in a different state, the first change is immediate, the last after settling.
+	go bounce(1, 0)
+	expect(true)
+	expect(false)
+	expectNone(t, changes, time.Millisecond*60)
+}
+
+func TestLayers(t *testing.T) {
+	d := testHID(3, 1)
+	d.Layer(1, 1, Suppress())
+	d.Layer(2, 3, ToggleLayer())
+	shift := d.OnClose(1)
+	base, shifted, opens := d.OnClose(2), d.OnClose(2, OnLayer(1)), d.OnOpen(2)
+	moved := d.OnMove(1, OnLayer(2))
+	go d.ParcelOutEvents()
+
+	d.InsertSyntheticEvent(1, 1, 0)
+	d.InsertSyntheticEvent(1, 1, 1)
+	<-shifted
+	d.InsertSyntheticEvent(0, 1, 0)
+	go d.InsertSyntheticEvent(0, 1, 1)
+	<-opens
+	d.InsertSyntheticEvent(1, 1, 1)
+	<-base
+	go d.InsertSyntheticEvent(0, 1, 1)
+	<-opens
+	expectNone(t, shift, time.Millisecond*20)
+
+	// toggled
+	d.InsertSyntheticEvent(1, 1, 2)
+	d.InsertSyntheticEvent(0, 1, 2)
+	go d.InsertSyntheticEvent(100, 2, 0)
+	if e := (<-moved).(CoordsEvent); e.X != 100/float32(maxValue) {
+		t.Errorf("expected move on layer 2, got %+v", e)
+	}
+	d.InsertSyntheticEvent(1, 1, 2)
+	d.InsertSyntheticEvent(0, 1, 2)
+	d.InsertSyntheticEvent(0, 2, 0)
+	expectNone(t, moved, time.Millisecond*20)
+}
+
+func TestSuppressedLayerState(t *testing.T) {
+	d := testHID(1, 0)
+	d.Layer(1, 1, Suppress())
+	done := make(chan bool)
+	go func() {
+		d.ParcelOutEvents()
+		close(done)
+	}()
+	d.InsertSyntheticEvent(1, 1, 0)
+	close(d.OSEvents)
+	<-done
+	if !d.ButtonClosed(0) || d.activeLayer() != 1 {
+		t.Error("expected suppressed shift button closed, and its layer active")
+	}
+}
+
+func TestTapHold(t *testing.T) {
+	d := testHID(3, 0)
+	tap, hold := d.OnTapHold(1, LongPressAfter(time.Millisecond*40))
+	tap2, hold2 := d.OnTapHold(2, LongPressAfter(time.Second), HoldOnOtherPress())
+	go d.ParcelOutEvents()
+
+	d.InsertSyntheticEvent(1, 1, 0)
+	go d.InsertSyntheticEvent(0, 1, 0)
+	if e := (<-tap).(ButtonEvent); e.Kind() != ButtonRoleTap {
+		t.Errorf("expected tap, got %+v", e)
+	}
+	d.InsertSyntheticEvent(1, 1, 0)
+	if e := (<-hold).(ButtonEvent); e.Kind() != ButtonRoleHold || e.Moment() != time.Millisecond*40 {
+		t.Errorf("expected hold at threshold, got %+v", e)
+	}
+	d.InsertSyntheticEvent(0, 1, 0)
+	expectNone(t, tap, time.Millisecond*20)
+
+	d.InsertSyntheticEvent(1, 1, 1)
+	go d.InsertSyntheticEvent(1, 1, 2)
+	<-hold2
+	d.InsertSyntheticEvent(0, 1, 2)
+	d.InsertSyntheticEvent(0, 1, 1)
+	expectNone(t, tap2, time.Millisecond*20)
+}
+
+func TestThresholdButton(t *testing.T) {
+	d := testHID(1, 1)
+	if d.ThresholdButton(1, 1, 1, .6, .1) {
+		t.Error("expected existing button not replaced")
+	}
+	d.ThresholdButton(5, 1, 1, .6, .1)
+	d.ThresholdButton(6, 1, 2, -.5, .1)
+	changes, doubles, longs := d.OnButton(5), d.OnDouble(5), d.OnLong(5)
+	left := d.OnClose(6)
+	done := make(chan bool)
+	go func() {
+		d.ParcelOutEvents()
+		close(done)
+	}()
+	move := func(ms uint32, axis uint8, v float32) {
+		d.OSEvents <- osEventRecord{Time: ms, Value: reading(v), Type: 2, Index: axis}
+	}
+	expect := func(closed bool) {
+		if e := (<-changes).(ButtonEvent); e.Closed != closed || e.Number != 5 {
+			t.Errorf("expected closed %v, got %+v", closed, e)
+		}
+	}
+	go move(200, 0, .7)
+	expect(true)
+	move(250, 0, .55) // within hysteresis
+	go move(300, 0, .4)
+	expect(false)
+	go move(340, 0, .7)
+	expect(true)
+	<-doubles
+	go move(1000, 0, 0)
+	expect(false)
+	<-longs
+	go move(1100, 1, -.6)
+	if e := (<-left).(ButtonEvent); e.Number != 6 || e.Moment() != time.Millisecond*1100 {
+		t.Errorf("expected button 6 closed, got %+v", e)
+	}
+	close(d.OSEvents)
+	<-done
+	if !d.ButtonClosed(5) || d.ButtonClosed(4) {
+		t.Error("expected only virtual button 6, index 5, closed")
+	}
+}
+
+func TestTimersAfterRouting(t *testing.T) {
+	d := testHID(0, 0)
+	done := make(chan bool)
+	go func() {
+		d.ParcelOutEvents()
+		close(done)
+	}()
+	close(d.OSEvents)
+	<-done
+	n := runtime.NumGoroutine()
+	d.after(0, func() { t.Error("ran after routing finished") })
+	time.Sleep(time.Millisecond * 20)
+	if runtime.NumGoroutine() > n {
+		t.Error("timer left waiting for routing")
+	}
+}
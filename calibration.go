@@ -0,0 +1,165 @@
+package joysticks
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// AxisCalibration is the raw driver readings of an axis at its extremes and at rest.
+type AxisCalibration struct {
+	Min, Center, Max int16
+}
+
+// Calibration of a HID's axes, by driver axis index.
+type Calibration map[uint8]AxisCalibration
+
+// file calibrations are saved in, and loaded from on Connect, keyed by device ID.
+var CalibrationFile = calibrationFile()
+
+func calibrationFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "joysticks", "calibration.json")
+}
+
+// ErrNotRouting is returned when ParcelOutEvents has finished, so calibrating can't be done.
+var ErrNotRouting = errors.New("joysticks: events no longer being routed")
+
+// StartCalibrating records the current position of all axes as their rest position, then records their extremes as they're moved.
+// needs ParcelOutEvents running, does nothing once it has finished.
+func (d HID) StartCalibrating() {
+	select {
+	case d.deferred <- func() {
+		for i, h := range d.HatAxes {
+			h.calibrating, h.observed = true, AxisCalibration{h.raw, h.raw, h.raw}
+			d.HatAxes[i] = h
+		}
+	}:
+	case <-d.done:
+	}
+}
+
+// StopCalibrating applies the calibration of the axes that have moved both ways from rest, (or one way, for axes like triggers that rest at an end,) since StartCalibrating, then saves it, to CalibrationFile, for the device.
+// needs ParcelOutEvents running, returning ErrNotRouting once it has finished.
+func (d HID) StopCalibrating() (Calibration, error) {
+	done := make(chan Calibration)
+	f := func() {
+		c := make(Calibration)
+		for i, h := range d.HatAxes {
+			if h.calibrating && h.observed.valid() {
+				c[i] = h.observed
+			}
+			h.calibrating = false
+			d.HatAxes[i] = h
+		}
+		d.Calibrate(c)
+		done <- c
+	}
+	select {
+	case d.deferred <- f:
+	case <-d.done:
+		return nil, ErrNotRouting
+	}
+	c := <-done
+	return c, d.saveCalibration(c)
+}
+
+// Calibrate axes, from then on their raw readings are normalised, using their calibration, before any other processing.
+// not thread safe, with ParcelOutEvents, so use before it runs.
+func (d HID) Calibrate(c Calibration) {
+	for i, ac := range c {
+		if h, ok := d.HatAxes[i]; ok {
+			h.calibration = ac
+			d.HatAxes[i] = h
+		}
+	}
+}
+
+// the file's calibrations, by device ID.
+func readCalibrations() (map[string]Calibration, error) {
+	cs := make(map[string]Calibration)
+	f, err := os.Open(CalibrationFile)
+	if err != nil {
+		return cs, err
+	}
+	defer f.Close()
+	return cs, json.NewDecoder(f).Decode(&cs)
+}
+
+func (d HID) saveCalibration(c Calibration) error {
+	cs, err := readCalibrations()
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	cs[d.ID] = c
+	if err := os.MkdirAll(filepath.Dir(CalibrationFile), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(CalibrationFile)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(cs); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// apply any calibration saved for the device.
+func (d HID) loadCalibration() {
+	if cs, err := readCalibrations(); err == nil {
+		d.Calibrate(cs[d.ID])
+	}
+}
+
+// record a reading in the extremes.
+func (c *AxisCalibration) observe(raw int16) {
+	if raw < c.Min {
+		c.Min = raw
+	}
+	if raw > c.Max {
+		c.Max = raw
+	}
+}
+
+// travel, from rest, needed for a side of an axis to count as calibrated, so jitter isn't mistaken for movement.
+const minTravel = maxValue / 8
+
+// if moved far enough, from rest, on both sides, or, when resting near an end, (like a trigger,) on the other side.
+func (c AxisCalibration) valid() bool {
+	below, above := int(c.Center)-int(c.Min) > minTravel, int(c.Max)-int(c.Center) > minTravel
+	atMin, atMax := int(c.Center)+maxValue <= minTravel, maxValue-int(c.Center) <= minTravel
+	return (below || above) && (below || atMin) && (above || atMax)
+}
+
+// a raw reading normalised to {-1...1}, each side of the rest position scaled separately.
+// an axis resting at an end, like a trigger, reads {0...1}, or {-1...0}, the side it can't move to reading as at rest.
+func (c AxisCalibration) normalise(raw int16) float32 {
+	if !c.valid() {
+		return float32(raw) / maxValue
+	}
+	var v float32
+	if raw < c.Center {
+		if int(c.Center)-int(c.Min) <= minTravel {
+			return 0
+		}
+		v = float32(int(raw)-int(c.Center)) / float32(int(c.Center)-int(c.Min))
+	} else {
+		if int(c.Max)-int(c.Center) <= minTravel {
+			return 0
+		}
+		v = float32(int(raw)-int(c.Center)) / float32(int(c.Max)-int(c.Center))
+	}
+	switch {
+	case v > 1:
+		return 1
+	case v < -1:
+		return -1
+	}
+	return v
+}
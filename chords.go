@@ -0,0 +1,141 @@
+package joysticks
+
+import (
+	"time"
+)
+
+// state of a chord of buttons.
+type chord struct {
+	buttons   []uint8                 // numbers
+	down      map[uint8]time.Duration // members closed, and when.
+	on        bool                    // all closed together, so chord event sent.
+	held      map[uint8]heldButton    // member closes held back, while they might still become part of the chord.
+	swallowed map[uint8]bool          // members with events suppressed, until they open.
+	layer     uint8                   // registered on, so only made while it's active.
+}
+
+// a button change, held back from routing.
+type heldButton struct {
+	index uint8
+	time  time.Duration
+	raw   int16
+}
+
+// chord, of all the buttons provided, event channel.
+// receives ButtonEvent's, with the chord number as the Source.Number, Closed when all the buttons are closed, having closed within the Chord Timing of each other, then not Closed when any one of them opens.
+// with the Suppress option, the buttons own events aren't sent when they make up the chord, so are held back, for the Chord Timing, until that's known.
+// (a button can only be suppressed by one chord, the first registered.)
+// options are provided using With; d.With(Suppress()).OnChord(1, 2, 9), with OnLayer the chord is only made while that layer is active.
+// re-registering the same buttons replaces the chord.
+func (d HID) OnChord(buttons ...uint8) chan Event {
+	n := uint8(len(d.chords) + 1)
+	for i, c := range d.chords {
+		if sameButtons(c.buttons, buttons) {
+			n = i
+		}
+	}
+	d.chords[n] = &chord{append([]uint8{}, buttons...), make(map[uint8]time.Duration), false, make(map[uint8]heldButton), make(map[uint8]bool), newSettings(d.Timing, d.opts).layer}
+	return d.register(ButtonChord, n, nil)
+}
+
+// update chords with a button change, sending any chord events, returns if the button's own events are to be held back, or suppressed.
+func (d HID) chorded(index uint8, t time.Duration, raw int16) (suppressed bool) {
+	number := d.Buttons[index].number
+	for n := uint8(1); int(n) <= len(d.chords); n++ {
+		c := d.chords[n]
+		if !c.has(number) {
+			continue
+		}
+		_, s, _ := d.channel(ButtonChord, n, c.layer)
+		if raw != 0 {
+			c.down[number] = t
+			if !c.on && len(c.down) == len(c.buttons) && c.spread() <= s.Chord && (c.layer == 0 || c.layer == d.activeLayer()) {
+				c.on = true
+				d.sendChord(n, t, raw)
+				if s.suppress && !suppressed {
+					for b := range c.held {
+						c.swallowed[b] = true
+						delete(c.held, b)
+					}
+					c.swallowed[number] = true
+				}
+			}
+		} else {
+			delete(c.down, number)
+			if c.on {
+				c.on = false
+				d.sendChord(n, t, raw)
+			}
+		}
+		if !s.suppress || suppressed {
+			continue
+		}
+		switch {
+		case c.swallowed[number]:
+			if raw == 0 {
+				delete(c.swallowed, number)
+			}
+			suppressed = true
+		case raw != 0:
+			h := heldButton{index, t, raw}
+			c.held[number] = h
+			d.after(s.Chord, func() {
+				if c.held[number] == h {
+					delete(c.held, number)
+					d.buttonChanged(h.index, h.time, h.raw)
+				}
+			})
+			suppressed = true
+		default:
+			// opened before the chord could be completed, so the held close goes first.
+			if h, ok := c.held[number]; ok {
+				delete(c.held, number)
+				d.buttonChanged(h.index, h.time, h.raw)
+			}
+		}
+	}
+	return
+}
+
+func (d HID) sendChord(n uint8, t time.Duration, raw int16) {
+	if c, _, ok := d.channel(ButtonChord, n, d.chords[n].layer); ok {
+		c <- ButtonEvent{when{t}, Source{ButtonChord, d.Index, n, 0, float32(raw), raw}, raw != 0}
+	}
+}
+
+func (c chord) has(number uint8) bool {
+	for _, b := range c.buttons {
+		if b == number {
+			return true
+		}
+	}
+	return false
+}
+
+// time between the first and last closes.
+func (c chord) spread() time.Duration {
+	var first, last time.Duration
+	started := false
+	for _, t := range c.down {
+		if !started || t < first {
+			first = t
+		}
+		if !started || t > last {
+			last = t
+		}
+		started = true
+	}
+	return last - first
+}
+
+func sameButtons(a, b []uint8) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, n := range a {
+		if !(chord{buttons: b}).has(n) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package joysticks
+
+import (
+	"math"
+)
+
+// Curve maps the magnitude of an axis's value, 0...1, to a new magnitude, the sign is kept.
+type Curve func(float32) float32
+
+// unchanged.
+func Linear(m float32) float32 {
+	return m
+}
+
+// magnitude raised to a power, >1 for finer control near the centre.
+func Power(exponent float32) Curve {
+	return func(m float32) float32 {
+		return float32(math.Pow(float64(m), float64(exponent)))
+	}
+}
+
+// blend of linear and cubic, expo as used for radio control, 0 is linear, 1 is cubic.
+func Expo(k float32) Curve {
+	return func(m float32) float32 {
+		return (1-k)*m + k*m*m*m
+	}
+}
+
+// S shaped, slower near both the centre and full deflection, faster in between, steepness >1.
+func SCurve(steepness float32) Curve {
+	return func(m float32) float32 {
+		a := math.Pow(float64(m), float64(steepness))
+		return float32(a / (a + math.Pow(float64(1-m), float64(steepness))))
+	}
+}
+
+// linearly interpolated between values evenly spaced from 0 to 1.
+func Table(values ...float32) Curve {
+	return func(m float32) float32 {
+		if len(values) < 2 {
+			return m
+		}
+		p := m * float32(len(values)-1)
+		i := int(p)
+		if i >= len(values)-1 {
+			return values[len(values)-1]
+		}
+		return values[i] + (values[i+1]-values[i])*(p-float32(i))
+	}
+}
+
+// SetCurve of a hat's axis (1 or 2), it's applied, after any deadzone, before any events are made from the axis.
+// set before ParcelOutEvents runs, it's not thread safe with it.
+func (d HID) SetCurve(hat, axis uint8, c Curve) {
+	for i, h := range d.HatAxes {
+		if h.number == hat && h.axis == axis {
+			h.curve = c
+			d.HatAxes[i] = h
+		}
+	}
+}
+
+// apply a curve to a value, keeping its sign.
+func (c Curve) apply(v float32) float32 {
+	if c == nil {
+		return v
+	}
+	if v < 0 {
+		return -c(-v)
+	}
+	return c(v)
+}
@@ -0,0 +1,72 @@
+package joysticks
+
+import (
+	"math"
+)
+
+// DeadzoneMode is the shape of a hat's deadzone.
+type DeadzoneMode uint8
+
+const (
+	NoDeadzone   DeadzoneMode = iota
+	Axial                     // each axis zero when within Inner, then rescaled.
+	Radial                    // both axes zero when within Inner of the centre.
+	ScaledRadial              // both axes zero when within Inner of the centre, then rescaled along the radius.
+)
+
+// Deadzone of a hat, Inner is the distance from the centre ignored, Outer is the distance from full deflection (1) that reads as full deflection, (for the radial modes, a radius of 1-Outer.)
+type Deadzone struct {
+	Mode         DeadzoneMode
+	Inner, Outer float32
+}
+
+// SetDeadzone of a hat, it's applied to the hat's position before any events are made from it.
+// not thread safe, with ParcelOutEvents, so set before it runs.
+func (d HID) SetDeadzone(hat uint8, dz Deadzone) {
+	d.deadzones[hat] = dz
+}
+
+// apply a hat's deadzone to its position.
+func (d HID) deadzoned(hat uint8, x, y float32) (float32, float32) {
+	dz, ok := d.deadzones[hat]
+	if !ok {
+		return x, y
+	}
+	switch dz.Mode {
+	case Axial:
+		return rescale(x, dz.Inner, 1-dz.Outer), rescale(y, dz.Inner, 1-dz.Outer)
+	case Radial, ScaledRadial:
+		// scaled along the radius, so the direction is kept.
+		r := float32(math.Hypot(float64(x), float64(y)))
+		if r <= dz.Inner {
+			return 0, 0
+		}
+		from := float32(0)
+		if dz.Mode == ScaledRadial {
+			from = dz.Inner
+		}
+		s := rescale(r, from, 1-dz.Outer) / r
+		return x * s, y * s
+	}
+	return rescale(x, 0, 1-dz.Outer), rescale(y, 0, 1-dz.Outer)
+}
+
+// map the magnitude of v from between from and to, to 0...1, keeping its sign.
+func rescale(v, from, to float32) float32 {
+	m := v
+	if m < 0 {
+		m = -m
+	}
+	switch {
+	case m <= from:
+		return 0
+	case m >= to:
+		m = 1
+	default:
+		m = (m - from) / (to - from)
+	}
+	if v < 0 {
+		return -m
+	}
+	return m
+}
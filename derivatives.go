@@ -0,0 +1,113 @@
+package joysticks
+
+import (
+	"time"
+)
+
+// most values kept for finding derivatives.
+const historyLength = 32
+
+// a value at a time.
+type sample struct {
+	t time.Duration
+	v float32
+}
+
+// add a value to a history, starting it with the value it changed from.
+func recorded(h []sample, from time.Duration, fv float32, t time.Duration, v float32) []sample {
+	if len(h) == 0 {
+		h = append(h, sample{from, fv})
+	}
+	if len(h) == historyLength {
+		h = append(h[:0:0], h[1:]...)
+	}
+	return append(h, sample{t, v})
+}
+
+// put velocity and acceleration events, of the kinds provided, found from a history, onto any registered channel(s).
+func (d HID) sendDerivatives(vk, ak Kind, layer uint8, src Source, t time.Duration, h []sample) {
+	for i, k := range []Kind{vk, ak} {
+		c, s, ok := d.channel(k, src.Number, layer)
+		if !ok {
+			continue
+		}
+		velocity, acceleration := derivatives(spaced(h, s.minStep), s.window)
+		v := velocity
+		if i == 1 {
+			v = acceleration
+		}
+		if s.smoothing > 0 {
+			sig := eventSignature{k, src.Number, s.layer}
+			f, ok := d.smoothers[sig]
+			if !ok {
+				f = &ema{tau: s.smoothing.Seconds()}
+				d.smoothers[sig] = f
+			}
+			var dt float64
+			if ok {
+				dt = (t - f.at).Seconds()
+				if dt < s.minStep.Seconds() {
+					dt = s.minStep.Seconds()
+				}
+			}
+			v, f.at = f.next(v, dt), t
+		}
+		src.kind = k
+		c <- AxisEvent{when{t}, src, v}
+	}
+}
+
+// a copy of a history, with the times of earlier values moved back, so values are at least step apart.
+func spaced(h []sample, step time.Duration) []sample {
+	s := make([]sample, len(h))
+	copy(s, h)
+	for i := len(s) - 2; i >= 0; i-- {
+		if s[i].t > s[i+1].t-step {
+			s[i].t = s[i+1].t - step
+		}
+	}
+	return s
+}
+
+// velocity, from a straight line, and acceleration, from a parabola, least-squares fitted to the values within a window before the last.
+// at least the last two values are used for velocity, three for acceleration, if there are fewer, the derivative is zero.
+func derivatives(h []sample, window time.Duration) (velocity, acceleration float32) {
+	if len(h) < 2 {
+		return
+	}
+	first := len(h) - 1
+	for first > 0 && (first > len(h)-3 || h[first-1].t >= h[len(h)-1].t-window) {
+		first--
+	}
+	h = h[first:]
+	// fit relative to the mean time and value, for accuracy.
+	var tm, vm float64
+	for _, s := range h {
+		tm += s.t.Seconds()
+		vm += float64(s.v)
+	}
+	tm /= float64(len(h))
+	vm /= float64(len(h))
+	var s2, s3, s4, sv, s2v float64 // sums of powers of time, and of them times value
+	for _, s := range h {
+		t, v := s.t.Seconds()-tm, float64(s.v)-vm
+		s2 += t * t
+		s3 += t * t * t
+		s4 += t * t * t * t
+		sv += t * v
+		s2v += t * t * v
+	}
+	if s2 == 0 {
+		return
+	}
+	velocity = float32(sv / s2)
+	if len(h) < 3 {
+		return
+	}
+	// v = b t + c (t²-s2/n), solved for c.
+	n := float64(len(h))
+	if det := s2*(s4-s2*s2/n) - s3*s3; det != 0 {
+		acceleration = float32(2 * (s2*s2v - s3*sv) / det)
+	}
+	return
+}
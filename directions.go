@@ -0,0 +1,76 @@
+package joysticks
+
+import (
+	"math"
+)
+
+// Direction of a hat, quantised from its position, clockwise from N, the way Y is negative on most devices.
+type Direction uint8
+
+const (
+	Neutral Direction = iota
+	N
+	NE
+	E
+	SE
+	S
+	SW
+	W
+	NW
+)
+
+// Hat direction event type.
+type DirectionEvent struct {
+	when
+	Source
+	Direction Direction
+}
+
+// hat direction changed event channel, ways (4, otherwise 8) directions, or Neutral, see ActivationRadius and Hysteresis.
+func (d HID) OnDirection(index uint8, ways uint8, opts ...Option) chan Event {
+	if ways != 4 {
+		ways = 8
+	}
+	return d.register(HatDirection, index, append(append([]Option{}, opts...), func(s *settings) { s.ways = ways }))
+}
+
+// the direction, out of ways (4 or 8), a position is in, Neutral if within radius of the centre.
+func direction(x, y float32, ways uint8, radius float32) Direction {
+	if x*x+y*y < radius*radius {
+		return Neutral
+	}
+	sectors := 2 * math.Pi / float64(ways)
+	sector := int(math.Floor(math.Atan2(float64(y), float64(x))/sectors + .5))
+	return Direction((sector*8/int(ways)+2+8)%8 + 1)
+}
+
+func (d Direction) diagonal() bool {
+	return d != Neutral && d%2 == 0
+}
+
+// directions next to each other, 45 degrees apart.
+func (d Direction) adjacent(o Direction) bool {
+	if d == Neutral || o == Neutral {
+		return false
+	}
+	diff := (int(d) - int(o) + 8) % 8
+	return diff == 1 || diff == 7
+}
+
+// angle of the centre of the direction's sector, radians, as AngleEvent's.
+func (d Direction) angle() float64 {
+	return float64(int(d)-3) * math.Pi / 4
+}
+
+// the direction, out of the settings ways, a position is in, staying in the current direction until moved more than the hysteresis past its sector.
+func (s settings) direction(current Direction, x, y float32) Direction {
+	d := direction(x, y, s.ways, s.radius)
+	if d == Neutral || current == Neutral || d == current {
+		return d
+	}
+	diff := math.Remainder(math.Atan2(float64(y), float64(x))-current.angle(), 2*math.Pi)
+	if math.Abs(diff) <= math.Pi/float64(s.ways)+float64(s.hysteresis) {
+		return current
+	}
+	return d
+}
@@ -17,6 +17,22 @@ Start running by calling 'ParcelOutEvents()'.
 
 (unlike highlevel, event index to channel mappings can be changed dynamically.)
 
+Options
+
+time dependent events use the HID's 'Timing', which starts as the package defaults, when connected.
+
+each On<xxx> method, and modifier, can take 'Option's, like 'LongPressAfter(d)', to change its settings from the defaults.
+
+'With(options...)' returns a HID that applies options to everything registered through it, including using methods, like OnChord, that can't take them directly.
+
+Multiple devices
+
+'ConnectBus(indexes...)' to several HIDs.
+
+Use 'On(HID.On<xxx>, index)' for a channel getting events from all the devices, or the methods of 'Device(index)' for one of them.
+
+Start running by calling the Bus's 'ParcelOutEvents()', which routes all the devices events, in timestamp order.
+
 Lowlevel
 
 'Connect' to a HID by index number.
@@ -27,7 +43,11 @@ Interface
 
 'Event' interface, provides a time.Duration through a call to the Moment() method, returning whatever the underlying Linux driver provides as the events timestamp, as a time.Duration.
 
-returned 'Event's need asserting to their underlying type ( '***Event' ) to access data other than moment.
+and its 'Kind', through Kind(), so events from different channels can be handled generically.
+
+returned 'Event's need asserting to their underlying type ( '***Event' ) to access data other than moment and kind.
+
+all the event types embed a 'Source', exposing the device, button/hat number, axis, normalised value and raw driver value, that caused them.
 
 */
 package joysticks
@@ -0,0 +1,71 @@
+package joysticks
+
+import (
+	"time"
+)
+
+// state of a dual-role button's current press.
+type dualRole struct {
+	deciding bool
+	presses  uint
+	layer    uint8
+	others   map[uint8]bool // buttons that closed while deciding.
+}
+
+// tap, or hold, of a dual-role button, event channels, only one of them gets an event for each press.
+// a press is a hold if the button is still closed after the LongPress Timing, otherwise a tap, when it opens.
+// with the HoldOnOtherPress option, any other button closing first makes it a hold, with the PermissiveHold option, another button closing and opening first does.
+// (hold events, decided by other buttons, are sent before those buttons own events.)
+func (d HID) OnTapHold(index uint8, opts ...Option) (tap, hold chan Event) {
+	return d.register(ButtonRoleTap, index, opts), d.register(ButtonRoleHold, index, opts)
+}
+
+// start, or finish, deciding the role of a dual-role button that has just changed.
+func (d HID) dualRoled(index uint8, b button) {
+	_, s, ok := d.channel(ButtonRoleHold, b.number, b.layer)
+	if !ok {
+		return
+	}
+	r, ok := d.dualRoles[b.number]
+	if !ok {
+		r = &dualRole{}
+		d.dualRoles[b.number] = r
+	}
+	if !b.value {
+		if r.deciding && r.presses == b.presses {
+			d.decideRole(b.number, r, ButtonRoleTap, b.time)
+		}
+		return
+	}
+	*r = dualRole{true, b.presses, b.layer, make(map[uint8]bool)}
+	d.after(s.LongPress, func() {
+		if r.deciding && r.presses == b.presses {
+			d.decideRole(b.number, r, ButtonRoleHold, b.time+s.LongPress)
+		}
+	})
+}
+
+// decide any dual-role buttons, that are deciding, using another button's change.
+func (d HID) dualRoleOthers(number uint8, t time.Duration, raw int16) {
+	for n, r := range d.dualRoles {
+		if !r.deciding || n == number {
+			continue
+		}
+		_, s, _ := d.channel(ButtonRoleHold, n, r.layer)
+		switch {
+		case raw != 0 && s.holdOnOtherPress:
+			d.decideRole(n, r, ButtonRoleHold, t)
+		case raw != 0:
+			r.others[number] = true
+		case r.others[number] && s.permissiveHold:
+			d.decideRole(n, r, ButtonRoleHold, t)
+		}
+	}
+}
+
+func (d HID) decideRole(number uint8, r *dualRole, k Kind, m time.Duration) {
+	r.deciding = false
+	if c, _, ok := d.channel(k, number, r.layer); ok {
+		c <- ButtonEvent{when{m}, Source{k, d.Index, number, 0, 1, 1}, true}
+	}
+}
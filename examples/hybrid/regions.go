@@ -2,57 +2,7 @@ package main
 
 import "github.com/splace/joysticks"
 import "log"
-import  "time"
-
-type Region struct{
-	minX,minY,maxX,maxY float32
-}
-
-type RegionEvent struct {
-	time time.Duration
-	Index int
-}
-
-func (b RegionEvent) Moment() time.Duration {
-	return b.time
-}
-
-
-func OnRegionChanged(trigger,position chan joysticks.Event, regions []Region) (chan RegionEvent,chan RegionEvent){
-	in := make(chan RegionEvent)
-	out := make(chan RegionEvent)
-	states:=make([]bool,len(regions))
-	var pevt joysticks.CoordsEvent
-	go func(){
-		for e:=range(position){
-			switch v := e.(type) {
-			case joysticks.CoordsEvent:
-				pevt=v
-		    }
-		}
-	}()
-	go func(){
-		for e:=range(trigger){
-			for ri,r:=range(regions){
-				if pevt.X>=r.minX && pevt.X<=r.maxX && pevt.Y>=r.minY && pevt.Y<=r.maxY{ 
-					if !states[ri]{
-						in <-RegionEvent{e.Moment(),ri}
-						states[ri]=true
-					}
-				}else{
-					if states[ri]{
-						out <-RegionEvent{e.Moment(),ri}
-						states[ri]=false
-					}
-				}
-			}
-		}
-		close(in)
-		close(out)
-	}()
-	return in,out
-}
-
+import "time"
 
 func main() {
 	device := joysticks.Connect(1)
@@ -63,26 +13,24 @@ func main() {
 	log.Printf("HID#1:- Buttons:%d, Hats:%d\n", len(device.Buttons), len(device.HatAxes)/2)
 
 	// make channels for specific events
-	regionEntering,regionExiting:=OnRegionChanged(device.OnHat(1),device.OnMove(1),[]Region{Region{.5,.5,1,1},Region{-1,-1,-.5,-.5}})
+	regions := joysticks.Regions(device.OnMove(1), []joysticks.Region{joysticks.Rect{MinX: .5, MinY: .5, MaxX: 1, MaxY: 1}, joysticks.Rect{MinX: -1, MinY: -1, MaxX: -.5, MaxY: -.5}})
 
-	// feed OS events onto the event channels. 
+	// feed OS events onto the event channels.
 	go device.ParcelOutEvents()
 
 	// handle event channels
-	go func(){
-		for{
-			select {
-			case r:= <-regionEntering:
-				log.Println("enter region:",r.Index)
-			case r:= <-regionExiting:
-				log.Println("leave region:",r.Index)
+	go func() {
+		for e := range regions {
+			r := e.(joysticks.RegionEvent)
+			if r.Kind() == joysticks.RegionEntered {
+				log.Println("enter region:", r.Region)
+			} else {
+				log.Println("leave region:", r.Region)
 			}
 		}
 	}()
-	
+
 	log.Println("Timeout in 10 secs.")
-	<-time.After(time.Second*10)
+	<-time.After(time.Second * 10)
 	log.Println("Shutting down due to timeout.")
 }
-
-
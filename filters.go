@@ -0,0 +1,121 @@
+package joysticks
+
+import (
+	"math"
+	"time"
+)
+
+// a filter of a stream of values, given the seconds since the last value, or zero for the first.
+type filter interface {
+	next(v float32, dt float64) float32
+}
+
+// wrap a chan of AxisEvent's and/or CoordsEvent's, returning a chan of the same events with their values filtered, (each of X and Y separately.)
+// events are treated as at least the MinStep option apart, (synthetic events all have the same Moment.)
+// other events are passed on unchanged. the returned chan is closed when the parameter chan is.
+func filtered(c chan Event, opts []Option, newFilter func() filter) chan Event {
+	s := newSettings(defaultTiming(), opts)
+	out := make(chan Event)
+	go func() {
+		defer close(out)
+		v, x, y := newFilter(), newFilter(), newFilter()
+		var last time.Duration
+		var started bool
+		for e := range c {
+			var dt float64
+			if started {
+				dt = (e.Moment() - last).Seconds()
+				if dt < s.minStep.Seconds() {
+					dt = s.minStep.Seconds()
+				}
+			}
+			switch fe := e.(type) {
+			case AxisEvent:
+				fe.V = v.next(fe.V, dt)
+				e = fe
+			case CoordsEvent:
+				fe.X, fe.Y = x.next(fe.X, dt), y.next(fe.Y, dt)
+				e = fe
+			default:
+				out <- e
+				continue
+			}
+			last, started = e.Moment(), true
+			out <- e
+		}
+	}()
+	return out
+}
+
+// EMA is an exponential moving average filter, values decay toward new readings with the time constant provided, so longer is smoother but lags more.
+func EMA(c chan Event, timeConstant time.Duration, opts ...Option) chan Event {
+	return filtered(c, opts, func() filter { return &ema{tau: timeConstant.Seconds()} })
+}
+
+type ema struct {
+	tau   float64
+	value float64
+	at    time.Duration // of the last value, when not filtering a chan
+}
+
+func (f *ema) next(v float32, dt float64) float32 {
+	if dt == 0 || f.tau <= 0 {
+		f.value = float64(v)
+	} else {
+		f.value += (float64(v) - f.value) * (1 - math.Exp(-dt/f.tau))
+	}
+	return float32(f.value)
+}
+
+// OneEuro is the 1€ filter, a low-pass filter whose cut-off frequency rises with speed, so slow movements are smooth and fast ones don't lag.
+// minCutoff (Hz) sets the smoothing when still, beta how much the cut-off rises with speed, and dCutoff (Hz) the smoothing of the speed estimate, (1 is usual.)
+func OneEuro(c chan Event, minCutoff, beta, dCutoff float32, opts ...Option) chan Event {
+	return filtered(c, opts, func() filter {
+		return &oneEuro{minCutoff: float64(minCutoff), beta: float64(beta), dCutoff: float64(dCutoff)}
+	})
+}
+
+type oneEuro struct {
+	minCutoff, beta, dCutoff float64
+	value, speed             float64
+}
+
+// smoothing factor, for a time step, of a low-pass filter with cut-off frequency fc.
+func lowPassAlpha(fc, dt float64) float64 {
+	tau := 1 / (2 * math.Pi * fc)
+	return 1 / (1 + tau/dt)
+}
+
+func (f *oneEuro) next(v float32, dt float64) float32 {
+	if dt == 0 {
+		f.value, f.speed = float64(v), 0
+		return v
+	}
+	f.speed += (((float64(v) - f.value) / dt) - f.speed) * lowPassAlpha(f.dCutoff, dt)
+	f.value += (float64(v) - f.value) * lowPassAlpha(f.minCutoff+f.beta*math.Abs(f.speed), dt)
+	return float32(f.value)
+}
+
+// Kalman is a simple Kalman filter, modelling the value as steady with random drift.
+// processNoise is how much the true value is expected to vary, (variance per second), measurementNoise how much readings are expected to vary from it, (variance), their ratio sets the smoothing.
+func Kalman(c chan Event, processNoise, measurementNoise float32, opts ...Option) chan Event {
+	return filtered(c, opts, func() filter { return &kalman{q: float64(processNoise), r: float64(measurementNoise)} })
+}
+
+type kalman struct {
+	q, r     float64
+	estimate float64
+	variance float64
+}
+
+func (f *kalman) next(v float32, dt float64) float32 {
+	if dt == 0 {
+		f.estimate, f.variance = float64(v), f.r
+		return v
+	}
+	f.variance += f.q * dt
+	gain := f.variance / (f.variance + f.r)
+	f.estimate += gain * (float64(v) - f.estimate)
+	f.variance *= 1 - gain
+	return float32(f.estimate)
+}
@@ -0,0 +1,366 @@
+package joysticks
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// raw reading for a normalised value.
+func reading(v float32) int16 {
+	return int16(v * maxValue)
+}
+
+func TestDeadzones(t *testing.T) {
+	d := testHID(0, 3)
+	d.SetDeadzone(1, Deadzone{ScaledRadial, .2, .1})
+	d.SetDeadzone(2, Deadzone{Axial, .2, 0})
+	d.SetDeadzone(3, Deadzone{Radial, 0, .1})
+	moved, centred, edged := d.OnMove(1), d.OnCenter(1), d.OnEdge(1)
+	moved2 := d.OnMove(2)
+	moved3, centred3 := d.OnMove(3), d.OnCenter(3)
+	go d.ParcelOutEvents()
+
+	// drift
+	d.InsertSyntheticEvent(reading(.1), 2, 0)
+	d.InsertSyntheticEvent(reading(.1), 2, 1)
+	expectNone(t, moved, time.Millisecond*20)
+
+	go d.InsertSyntheticEvent(reading(.6), 2, 0)
+	if e := (<-moved).(CoordsEvent); e.X < .5 || e.X > .6 || e.Y < .08 || e.Y > .1 {
+		t.Errorf("expected scaled position, got %+v", e)
+	}
+	// within the outer deadzone, full deflection, in the same direction.
+	go d.InsertSyntheticEvent(reading(.95), 2, 0)
+	if e := (<-moved).(CoordsEvent); e.X*e.X+e.Y*e.Y < .999 || e.X*e.X+e.Y*e.Y > 1.001 || e.Y/e.X < .104 || e.Y/e.X > .107 {
+		t.Errorf("expected radius 1, at the same angle, got %+v", e)
+	}
+	go d.InsertSyntheticEvent(reading(0), 2, 1)
+	<-moved
+	if e := (<-edged).(AngleEvent); e.Angle != 0 {
+		t.Errorf("expected edge, got %+v", e)
+	}
+	go d.InsertSyntheticEvent(reading(.05), 2, 0)
+	<-moved
+	<-centred
+
+	go d.InsertSyntheticEvent(reading(.6), 2, 3)
+	if e := (<-moved2).(CoordsEvent); e.X != 0 || e.Y < .49 || e.Y > .51 {
+		t.Errorf("expected axial position, got %+v", e)
+	}
+
+	// outer deadzone only, back to the centre.
+	go d.InsertSyntheticEvent(reading(.5), 2, 4)
+	<-moved3
+	go d.InsertSyntheticEvent(reading(0), 2, 4)
+	if e := (<-moved3).(CoordsEvent); e.X != 0 || e.Y != 0 {
+		t.Errorf("expected centred, got %+v", e)
+	}
+	<-centred3
+}
+
+func TestCurves(t *testing.T) {
+	for _, c := range []struct {
+		Curve
+		in, out float32
+	}{
+		{Linear, .5, .5},
+		{Power(2), .5, .25},
+		{Expo(1), .5, .125},
+		{SCurve(2), .25, .1},
+		{SCurve(2), .5, .5},
+		{Table(0, .2, 1), .25, .1},
+		{Table(0, .2, 1), .75, .6},
+		{Table(0, .2, 1), 1, 1},
+	} {
+		if v := c.apply(-c.in); v > .001-c.out || v < -.001-c.out {
+			t.Errorf("%v expected %v, got %v", c.in, -c.out, v)
+		}
+	}
+	d := testHID(0, 1)
+	d.SetCurve(1, 2, Power(2))
+	moved, rotated := d.OnMove(1), d.OnRotate(1)
+	go d.ParcelOutEvents()
+	go d.InsertSyntheticEvent(reading(.5), 2, 0)
+	<-moved
+	<-rotated
+	go d.InsertSyntheticEvent(reading(.5), 2, 1)
+	if e := (<-moved).(CoordsEvent); e.Y > e.X/2+.001 || e.Y < e.X/2-.001 {
+		t.Errorf("expected Y curved, got %+v", e)
+	}
+	if e := (<-rotated).(AngleEvent); e.Angle > .47 || e.Angle < .45 {
+		t.Errorf("expected angle of curved position, got %+v", e)
+	}
+}
+
+func TestCalibration(t *testing.T) {
+	file := CalibrationFile
+	t.Cleanup(func() { CalibrationFile = file })
+	CalibrationFile = t.TempDir() + "/calibration.json"
+	d := testHID(0, 1)
+	d.Name, d.ID = "test stick", "test stick 1"
+	moved, edged, centred := d.OnMove(1), d.OnEdge(1), d.OnCenter(1)
+	go d.ParcelOutEvents()
+	go d.InsertSyntheticEvent(983, 2, 0)
+	<-moved
+	d.StartCalibrating()
+	for _, v := range []int16{-30000, 983, 29000, 983} {
+		go d.InsertSyntheticEvent(v, 2, 0)
+		<-moved
+	}
+	c, err := d.StopCalibrating()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c[0] != (AxisCalibration{-30000, 983, 29000}) || len(c) != 1 {
+		t.Errorf("expected one axis calibrated, got %+v", c)
+	}
+	go d.InsertSyntheticEvent(29000, 2, 0)
+	if e := (<-moved).(CoordsEvent); e.X != 1 {
+		t.Errorf("expected full deflection, got %+v", e)
+	}
+	<-edged
+	go d.InsertSyntheticEvent(983, 2, 0)
+	<-moved
+	<-centred
+
+	// loaded for the same device
+	d2 := testHID(0, 1)
+	d2.Name, d2.ID = d.Name, d.ID
+	d2.loadCalibration()
+	if d2.HatAxes[0].calibration != c[0] {
+		t.Errorf("expected loaded calibration, got %+v", d2.HatAxes[0].calibration)
+	}
+	// not for another of the same model
+	d3 := testHID(0, 1)
+	d3.Name, d3.ID = d.Name, "test stick 2"
+	d3.loadCalibration()
+	if d3.HatAxes[0].calibration != (AxisCalibration{}) {
+		t.Errorf("expected no calibration, got %+v", d3.HatAxes[0].calibration)
+	}
+}
+
+func TestOneSidedCalibration(t *testing.T) {
+	trigger := AxisCalibration{-32000, -32000, 32000}
+	if !trigger.valid() {
+		t.Fatal("expected a trigger, resting at an end, to be calibrated")
+	}
+	for raw, v := range map[int16]float32{-32767: 0, -32000: 0, 32000: 1, 0: .5} {
+		if n := trigger.normalise(raw); n < v-1e-3 || n > v+1e-3 {
+			t.Errorf("expected %v normalised to %v, got %v", raw, v, n)
+		}
+	}
+	if jitter := (AxisCalibration{-10, 0, 12}); jitter.valid() {
+		t.Error("expected jitter not to count as calibrated")
+	}
+	if oneWay := (AxisCalibration{-100, 0, 30000}); oneWay.valid() {
+		t.Error("expected a centred axis, moved only one way, not to count as calibrated")
+	}
+}
+
+func TestCalibratingAfterRouting(t *testing.T) {
+	d := testHID(0, 1)
+	done := make(chan bool)
+	go func() {
+		d.ParcelOutEvents()
+		close(done)
+	}()
+	close(d.OSEvents)
+	<-done
+	d.StartCalibrating()
+	if _, err := d.StopCalibrating(); err != ErrNotRouting {
+		t.Errorf("expected ErrNotRouting, got %v", err)
+	}
+}
+
+func TestTransforms(t *testing.T) {
+	d := testHID(0, 2)
+	d.InvertAxis(1, 1, true)
+	d.SwapHatAxes(1, true)
+	d.RotateHat(2, math.Pi/2)
+	moved, edged := d.OnMove(1), d.OnEdge(1)
+	moved2, panX2 := d.OnMove(2), d.OnPanX(2)
+	go d.ParcelOutEvents()
+
+	// inverted X, then swapped, moves Y negative
+	go d.InsertSyntheticEvent(reading(1), 2, 0)
+	if e := (<-moved).(CoordsEvent); e.X != 0 || e.Y != -1 {
+		t.Errorf("expected 0,-1, got %+v", e)
+	}
+	if e := (<-edged).(AngleEvent); e.Angle > -1.57 || e.Angle < -1.58 {
+		t.Errorf("expected edge at -π/2, got %+v", e)
+	}
+
+	// rotated a quarter turn, so X moves only Y.
+	go d.InsertSyntheticEvent(reading(.5), 2, 2)
+	if e := (<-panX2).(AxisEvent); e.Axis != 2 || e.V < .49 || e.V > .51 {
+		t.Errorf("expected axis 2 to .5, got %+v", e)
+	}
+	if e := (<-moved2).(CoordsEvent); e.X != 0 || e.Y < .49 || e.Y > .51 {
+		t.Errorf("expected 0,.5, got %+v", e)
+	}
+	go d.InsertSyntheticEvent(reading(.5), 2, 3)
+	if e := (<-moved2).(CoordsEvent); e.X < -.51 || e.X > -.49 || e.Y < .49 || e.Y > .51 {
+		t.Errorf("expected -.5,.5, got %+v", e)
+	}
+}
+
+func TestDerivatives(t *testing.T) {
+	ms := func(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+	var ramp, parabola, same []sample
+	for i := 0; i < 10; i++ {
+		ramp = append(ramp, sample{ms(i * 10), float32(i) * .02})
+		tt := float32(i) * .01
+		parabola = append(parabola, sample{ms(i * 10), 3 * tt * tt})
+		same = append(same, sample{ms(100), float32(i) * .01})
+	}
+	near := func(a, b float32) bool { return a-b < .01 && b-a < .01 }
+	if v, a := derivatives(spaced(ramp, ms(5)), ms(50)); !near(v, 2) || !near(a, 0) {
+		t.Errorf("ramp expected 2,0 got %v,%v", v, a)
+	}
+	if _, a := derivatives(spaced(parabola, ms(5)), ms(50)); !near(a, 6) {
+		t.Errorf("parabola expected acceleration 6 got %v", a)
+	}
+	// same timestamps, spread by the minimum step.
+	if v, _ := derivatives(spaced(same, ms(5)), ms(50)); !near(v, 2) {
+		t.Errorf("same times expected 2, got %v", v)
+	}
+	if v, a := derivatives(spaced(same[:1], ms(5)), ms(50)); v != 0 || a != 0 {
+		t.Errorf("single value expected zero, got %v,%v", v, a)
+	}
+
+	d := testHID(0, 1)
+	speed, accel := d.OnSpeedX(1, MinStep(ms(10))), d.OnAccelerationX(1, MinStep(ms(10)))
+	smoothed := d.OnSpeedY(1, MinStep(ms(10)), SmoothDerivative(ms(100)))
+	go d.ParcelOutEvents()
+	// synthetic events, so all at the same moment.
+	for i := 1; i <= 3; i++ {
+		go d.InsertSyntheticEvent(reading(float32(i)*.1), 2, 1)
+		if e := (<-speed).(AxisEvent); e.Kind() != HatVelocityX || !near(e.V, 10) {
+			t.Errorf("expected speed 10, got %+v", e)
+		}
+		if e := (<-accel).(AxisEvent); e.Kind() != HatAccelerationX || e.V > 1 || e.V < -1 {
+			t.Errorf("expected no acceleration, beyond rounding, got %+v", e)
+		}
+	}
+	go d.InsertSyntheticEvent(reading(.1), 2, 0)
+	first := (<-smoothed).(AxisEvent).V
+	go d.InsertSyntheticEvent(reading(.3), 2, 0)
+	if e := (<-smoothed).(AxisEvent); e.V <= first || e.V >= 15 {
+		t.Errorf("expected smoothed speed, between %v and 15, got %+v", first, e)
+	}
+}
+
+func TestDirections(t *testing.T) {
+	at := func(degrees float64, r float32) (float32, float32) {
+		s, c := math.Sincos(degrees * math.Pi / 180)
+		return r * float32(c), r * float32(s)
+	}
+	s8 := newSettings(Timing{}, []Option{Hysteresis(math.Pi / 36)})
+	s4 := newSettings(Timing{}, []Option{func(s *settings) { s.ways = 4 }, ActivationRadius(.2), Hysteresis(0)})
+	for _, c := range []struct {
+		settings
+		current   Direction
+		degrees   float64
+		r         float32
+		direction Direction
+	}{
+		{s8, Neutral, -90, .3, Neutral},
+		{s8, Neutral, -90, .8, N},
+		{s8, N, -65, .8, N}, // past the boundary, within hysteresis
+		{s8, N, -60, .8, NE},
+		{s8, NE, -70, .8, NE},
+		{s8, NE, -70, .3, Neutral},
+		{s8, W, -175, .8, W}, // either side of ±π
+		{s8, W, 170, .8, W},
+		{s4, Neutral, 40, .3, E},
+		{s4, E, 46, .3, S},
+		{s4, S, 44, .3, E},
+	} {
+		x, y := at(c.degrees, c.r)
+		if d := c.settings.direction(c.current, x, y); d != c.direction {
+			t.Errorf("from %v at %v°, expected %v, got %v", c.current, c.degrees, c.direction, d)
+		}
+	}
+
+	d := testHID(0, 1)
+	dirs := d.OnDirection(1, 4)
+	go d.ParcelOutEvents()
+	go d.InsertSyntheticEvent(reading(-.8), 2, 1)
+	if e := (<-dirs).(DirectionEvent); e.Direction != N || e.Kind() != HatDirection {
+		t.Errorf("expected N, got %+v", e)
+	}
+	go d.InsertSyntheticEvent(reading(.6), 2, 0)
+	expectNone(t, dirs, time.Millisecond*20)
+	go d.InsertSyntheticEvent(reading(0), 2, 1)
+	if e := (<-dirs).(DirectionEvent); e.Direction != E {
+		t.Errorf("expected E, got %+v", e)
+	}
+	go d.InsertSyntheticEvent(reading(0), 2, 0)
+	if e := (<-dirs).(DirectionEvent); e.Direction != Neutral {
+		t.Errorf("expected Neutral, got %+v", e)
+	}
+}
+
+func TestButtonAxis(t *testing.T) {
+	d := testHID(4, 1)
+	if d.ButtonAxis(1, 1, 1, 2) {
+		t.Error("expected real hat not replaced")
+	}
+	d.ButtonAxis(2, 1, 1, 2)
+	d.ButtonAxis(2, 2, 3, 4, RampUp(10), RampDown(20), RampStep(time.Millisecond*20), OpposingPresses(LastPressWins))
+	if d.ButtonAxis(2, 1, 3, 4) {
+		t.Error("expected axis not replaced")
+	}
+	moved := d.OnMove(2)
+	go d.ParcelOutEvents()
+	near := func(a, b float32) bool { return a-b < .001 && b-a < .001 }
+	expect := func(x, y float32) {
+		if e := (<-moved).(CoordsEvent); !near(e.X, x) || !near(e.Y, y) {
+			t.Errorf("expected %v,%v, got %+v", x, y, e)
+		}
+	}
+	for _, c := range []struct {
+		button uint8
+		closed int16
+		x      float32
+	}{{2, 1, 1}, {1, 1, 0}, {2, 0, -1}, {1, 0, 0}} {
+		go d.InsertSyntheticEvent(c.closed, 1, c.button-1)
+		expect(c.x, 0)
+	}
+
+	// ramped, each step 20ms later
+	go d.InsertSyntheticEvent(1, 1, 3)
+	for i := 1; i <= 5; i++ {
+		e := (<-moved).(CoordsEvent)
+		if !near(e.Y, float32(i)*.2) || e.Moment() != time.Millisecond*20*time.Duration(i) {
+			t.Errorf("expected ramp to %v at %v, got %+v", float32(i)*.2, time.Millisecond*20*time.Duration(i), e)
+		}
+	}
+	expectNone(t, moved, time.Millisecond*60)
+	// the last press wins, ramping down to the centre, then up the other way.
+	go func() { d.OSEvents <- osEventRecord{Time: 200, Value: 1, Type: 1, Index: 2} }()
+	for _, y := range []float32{.6, .2, 0, -.2} {
+		expect(0, y)
+	}
+}
+
+func TestUnpairedAxis(t *testing.T) {
+	d := testHID(0, 1)
+	d.HatAxes[2] = hatAxis{number: 2, axis: 1} // a throttle
+	moved := d.OnMove(2)
+	done := make(chan bool)
+	go func() {
+		d.ParcelOutEvents()
+		close(done)
+	}()
+	go d.InsertSyntheticEvent(reading(.5), 2, 2)
+	if e := (<-moved).(CoordsEvent); e.X < .49 || e.X > .51 || e.Y != 0 {
+		t.Errorf("expected .5,0, got %+v", e)
+	}
+	close(d.OSEvents)
+	<-done
+	if len(d.HatAxes) != 3 || d.HatExists(0) {
+		t.Errorf("expected no axis added, got %+v", d.HatAxes)
+	}
+}
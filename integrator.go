@@ -0,0 +1,140 @@
+package joysticks
+
+import (
+	"time"
+)
+
+// Integrator integrates the velocities, from the CoordsEvent's on a chan, into a position, sending it, when it changes, as CoordsEvent's of kind Integrated, on its Events chan, every Velocity Timing.
+// the position can be limited, or wrapped, and the velocity scaled, with the LimitX/Y, WrapX/Y and Gain options.
+// Events is closed, and integration stops, when the chan integrated is closed.
+type Integrator struct {
+	Events  chan Event
+	control chan func(*integration)
+	done    chan struct{}
+}
+
+// state of an Integrator, only accessed from its go routine.
+type integration struct {
+	x, y, vx, vy float32
+	at           time.Time // position integrated up to
+	moment       time.Duration
+	began        time.Time // when moment was
+	changed      bool
+	limits       [2]*limit
+}
+
+// range of an axis, of an integrated position.
+type limit struct {
+	min, max float32
+	wrap     bool
+}
+
+// apply a limit to a value.
+func (l *limit) apply(v float32) float32 {
+	if l == nil {
+		return v
+	}
+	if l.wrap {
+		if span := l.max - l.min; span > 0 {
+			for v >= l.max {
+				v -= span
+			}
+			for v < l.min {
+				v += span
+			}
+		}
+		return v
+	}
+	if v > l.max {
+		return l.max
+	}
+	if v < l.min {
+		return l.min
+	}
+	return v
+}
+
+// NewIntegrator starts integrating the velocities on the chan provided, from position 0,0.
+func NewIntegrator(c chan Event, opts ...Option) *Integrator {
+	s := newSettings(defaultTiming(), opts)
+	i := &Integrator{Events: make(chan Event), control: make(chan func(*integration)), done: make(chan struct{})}
+	go func() {
+		defer close(i.Events)
+		defer close(i.done)
+		st := integration{limits: s.limits}
+		// move the position on to now, at the current velocity.
+		integrate := func() {
+			now := s.clock.Now()
+			if !st.at.IsZero() && (st.vx != 0 || st.vy != 0) {
+				dt := float32(now.Sub(st.at).Seconds())
+				st.x, st.y = st.limits[0].apply(st.x+st.vx*dt), st.limits[1].apply(st.y+st.vy*dt)
+				st.changed = true
+			}
+			st.at = now
+		}
+		var tick <-chan time.Time
+		var out chan Event // nil, so not sending, unless there is a position pending
+		var pending Event
+		for {
+			select {
+			case e, ok := <-c:
+				if !ok {
+					return
+				}
+				ce, ok := e.(CoordsEvent)
+				if !ok {
+					continue
+				}
+				integrate()
+				st.vx, st.vy = ce.X*s.gain, ce.Y*s.gain
+				st.moment, st.began = e.Moment(), st.at
+				if tick == nil {
+					tick = s.clock.After(s.Velocity)
+				}
+			case f := <-i.control:
+				integrate()
+				f(&st)
+			case out <- pending:
+				out = nil
+			case <-tick:
+				integrate()
+				if st.changed {
+					st.changed = false
+					pending, out = CoordsEvent{when{st.moment + st.at.Sub(st.began)}, Source{kind: Integrated, Value: st.x}, st.x, st.y}, i.Events
+				}
+				tick = s.clock.After(s.Velocity)
+			}
+		}
+	}()
+	return i
+}
+
+// run a function on the integration state, waiting for it to finish, unless integration has stopped.
+func (i *Integrator) do(f func(*integration)) {
+	ran := make(chan struct{})
+	select {
+	case i.control <- func(st *integration) { f(st); close(ran) }:
+		<-ran
+	case <-i.done:
+	}
+}
+
+// Reset the position to 0,0.
+func (i *Integrator) Reset() {
+	i.Set(0, 0)
+}
+
+// Set the position, (limits still apply.)
+func (i *Integrator) Set(x, y float32) {
+	i.do(func(st *integration) {
+		st.x, st.y, st.changed = st.limits[0].apply(x), st.limits[1].apply(y), true
+	})
+}
+
+// Position currently integrated to.
+func (i *Integrator) Position() (x, y float32) {
+	i.do(func(st *integration) {
+		x, y = st.x, st.y
+	})
+	return
+}
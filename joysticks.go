@@ -3,7 +3,6 @@ package joysticks
 import (
 	"math"
 	"time"
-	//"fmt"
 )
 
 var LongPressDelay = time.Second / 2
@@ -14,53 +13,134 @@ type hatAxis struct {
 	axis     uint8
 	reversed bool
 	time     time.Duration
-	value    float32
+	value    float32 // after processing
+	input    float32 // before processing
+	raw      int16
+	curve    Curve
+	history  []sample // recent values, for derivatives
+	// calibration
+	calibration AxisCalibration
+	calibrating bool
+	observed    AxisCalibration
 }
 
 type button struct {
-	number uint8
-	time   time.Duration
-	value  bool
-}
-
-type eventType uint8
+	number  uint8
+	time    time.Duration
+	value   bool
+	presses uint // count of closes, so timers can tell if they're for the current press.
+	taps    uint // closes in the current sequence of taps.
+	latched bool
+	layer   uint8 // active when it closed
+	// debouncing
+	settle   time.Duration
+	settling bool
+	passed   int16      // last value passed on
+	latest   heldButton // last change
+}
+
+// Kind of an event, there is one for each On<xxx> registering method, and some for events produced by modifiers.
+type Kind uint8
 
 const (
-	buttonChange eventType = iota
-	buttonClose
-	buttonOpen
-	buttonLongPress
-	buttonDoublePress
-	hatChange
-	hatPanX
-	hatPanY
-	hatPosition
-	hatAngle
-	hatRadius
-	hatCentered
-	hatEdge
-	hatVelocityX
-	hatVelocityY
+	ButtonChange Kind = iota
+	ButtonClose
+	ButtonOpen
+	ButtonLongPress
+	ButtonDoublePress
+	ButtonHold
+	ButtonHoldProgress
+	ButtonTap
+	ButtonTaps
+	ButtonChord
+	ButtonToggle
+	ButtonRoleTap
+	ButtonRoleHold
+	Sequenced
+	HatChange
+	HatPanX
+	HatPanY
+	HatPosition
+	HatAngle
+	HatRadius
+	HatCentered
+	HatEdge
+	HatVelocityX
+	HatVelocityY
+	HatAccelerationX
+	HatAccelerationY
+	HatDirection
+	Repeat
+	Integrated
+	Highlighted
+	Selected
+	RegionEntered
+	RegionExited
 )
 
 // signature of an event
 type eventSignature struct {
-	eventType
+	Kind
 	number uint8
+	layer  uint8
 }
 
 // HID holds the in-coming event channel, available button and hat indexes, and registered events, for a human interface device.
 // It has methods to control and adjust behaviour.
 type HID struct {
-	OSEvents chan osEventRecord
-	Buttons  map[uint8]button
-	HatAxes  map[uint8]hatAxis
-	Events   map[eventSignature]chan Event
+	OSEvents    chan osEventRecord
+	Buttons     map[uint8]button
+	HatAxes     map[uint8]hatAxis
+	Events      map[eventSignature]chan Event
+	Index       int    // the index it was connected with, so identifies the device events came from.
+	Name        string // from the driver, identifies the model of device.
+	ID          string // the Name, and the device's serial number, or, without one, the port it's plugged into, so, for example, its saved calibration.
+	Timing      Timing // defaults for event registrations, changes only effect later registrations.
+	settings    map[eventSignature]settings
+	deferred    chan func()   // functions to be run by the routing go routine, from timers.
+	done        chan struct{} // closed when routing has finished, so timers don't wait for it.
+	opts        []Option      // applied to all registrations, before their own.
+	chords      map[uint8]*chord
+	recognizers map[uint8]*recognizer
+	layers      map[uint8]*shiftLayer
+	dualRoles   map[uint8]*dualRole
+	deadzones   map[uint8]Deadzone
+	transforms  map[uint8]hatTransform
+	smoothers   map[eventSignature]*ema // of derivatives
+	directions  map[eventSignature]Direction
+	thresholds  map[uint8]*threshold  // by button index
+	buttonAxes  map[uint8]*buttonAxis // by hat axis index
+}
+
+// make a HID, with nothing available and no registered events.
+func newHID(index int) *HID {
+	return &HID{
+		OSEvents:    make(chan osEventRecord),
+		Buttons:     make(map[uint8]button),
+		HatAxes:     make(map[uint8]hatAxis),
+		Events:      make(map[eventSignature]chan Event),
+		Index:       index,
+		Timing:      defaultTiming(),
+		settings:    make(map[eventSignature]settings),
+		deferred:    make(chan func()),
+		done:        make(chan struct{}),
+		chords:      make(map[uint8]*chord),
+		recognizers: make(map[uint8]*recognizer),
+		layers:      make(map[uint8]*shiftLayer),
+		dualRoles:   make(map[uint8]*dualRole),
+		deadzones:   make(map[uint8]Deadzone),
+		transforms:  make(map[uint8]hatTransform),
+		smoothers:   make(map[eventSignature]*ema),
+		directions:  make(map[eventSignature]Direction),
+		thresholds:  make(map[uint8]*threshold),
+		buttonAxes:  make(map[uint8]*buttonAxis),
+	}
 }
 
-// Events always have the time they occurred.
+// Events always have the time they occurred, and the kind they are.
 type Event interface {
 	Moment() time.Duration
+	Kind() Kind
 }
 
 type when struct {
@@ -71,161 +151,280 @@ func (b when) Moment() time.Duration {
 	return b.Time
 }
 
+// Source identifies where an event came from; the device, the button or hat number and, for hats, the axis.
+// Value is the reading that caused the event, normalised to {-1...1} for hats and {0,1} for buttons, Raw is that reading as provided by the driver.
+type Source struct {
+	kind   Kind
+	Device int
+	Number uint8
+	Axis   uint8
+	Value  float32
+	Raw    int16
+}
+
+func (s Source) Kind() Kind {
+	return s.kind
+}
 
-// button changed
+// Origin returns the source, so can be used to get it from any of the event types.
+func (s Source) Origin() Source {
+	return s
+}
+
+// button changed, or, for open/close/long/double events, just the button's new state.
 type ButtonEvent struct {
 	when
-	number uint8
-	value  bool
+	Source
+	Closed bool
 }
 
-// hat changed
+// hat axis changed, also used as the event for a hat returning to the centre.
 type HatEvent struct {
 	when
-	number uint8
-	axis   uint8
-	value  float32
+	Source
 }
 
 // Hat position event type. X,Y{-1...1}
 type CoordsEvent struct {
 	when
+	Source
 	X, Y float32
 }
 
 // Hat Axis event type. V{-1...1}
 type AxisEvent struct {
 	when
+	Source
 	V float32
 }
 
 // Hat angle event type. Angle{-Pi...Pi}
 type AngleEvent struct {
 	when
+	Source
 	Angle float32
 }
 
 // Hat radius event type. Radius{0...√2}
 type RadiusEvent struct {
 	when
+	Source
 	Radius float32
 }
 
 // ParcelOutEvents waits on the HID.OSEvents channel (so is blocking), then puts any events matching onto any registered channel(s).
+// returns when the OSEvents channel is closed.
 func (d HID) ParcelOutEvents() {
-	for evt := range d.OSEvents {
-		switch evt.Type {
-		case 1:
-			b := d.Buttons[evt.Index]
-			if c, ok := d.Events[eventSignature{buttonChange, b.number}]; ok {
-				c <- ButtonEvent{when{toDuration(evt.Time)}, b.number, evt.Value == 1}
-			}
-			if evt.Value == 0 {
-				if c, ok := d.Events[eventSignature{buttonOpen, b.number}]; ok {
-					c <- when{toDuration(evt.Time)}
-				}
-				if c, ok := d.Events[eventSignature{buttonLongPress, b.number}]; ok {
-					if toDuration(evt.Time) > b.time+LongPressDelay {
-						c <- when{toDuration(evt.Time)}
-					}
-				}
+	defer close(d.done)
+	for {
+		select {
+		case evt, ok := <-d.OSEvents:
+			if !ok {
+				return
 			}
-			if evt.Value == 1 {
-				if c, ok := d.Events[eventSignature{buttonClose, b.number}]; ok {
-					c <- when{toDuration(evt.Time)}
-				}
-				if c, ok := d.Events[eventSignature{buttonDoublePress, b.number}]; ok {
-					if toDuration(evt.Time) < b.time+DoublePressDelay {
-						c <- when{toDuration(evt.Time)}
-					}
-				}
+			d.route(evt)
+		case f := <-d.deferred:
+			f()
+		}
+	}
+}
+
+// put the events an OS event causes onto any registered channel(s).
+func (d HID) route(evt osEventRecord) {
+	switch evt.Type {
+	case 1:
+		d.buttonInput(evt.Index, toDuration(evt.Time), evt.Value)
+	case 2:
+		d.hatInput(evt.Index, toDuration(evt.Time), evt.Value)
+	default:
+		// log.Println("unknown input type. ",evt.Type & 0x7f)
+	}
+}
+
+// a hat axis has changed, process its hat's position, then send the events that causes.
+func (d HID) hatInput(index uint8, t time.Duration, raw int16) {
+	h := d.HatAxes[index]
+	h.raw = raw
+	if h.calibrating {
+		h.observed.observe(raw)
+	}
+	h.input = h.calibration.normalise(raw)
+	if h.reversed {
+		h.input = -h.input
+	}
+	d.HatAxes[index] = h
+	xi, yi, paired := d.hatPair(index)
+	x, y := d.HatAxes[xi].input, d.HatAxes[yi].input
+	x, y = d.transformed(h.number, x, y)
+	x, y = d.deadzoned(h.number, x, y)
+	x, y = d.HatAxes[xi].curve.apply(x), d.HatAxes[yi].curve.apply(y)
+	// a missing axis stays centred.
+	if !paired && h.axis == 1 {
+		y = 0
+	}
+	if !paired && h.axis == 2 {
+		x = 0
+	}
+	if x == d.HatAxes[xi].value && y == d.HatAxes[yi].value {
+		return
+	}
+	d.hatChanged(index, t, raw, x, y)
+}
+
+// indexes of the X and Y axes of the hat an axis is on, and if the other one exists, (not all axes are paired, like a throttle.)
+func (d HID) hatPair(index uint8) (x, y uint8, paired bool) {
+	other := index - 1
+	x, y = other, index
+	if d.HatAxes[index].axis == 1 {
+		other = index + 1
+		x, y = index, other
+	}
+	o, paired := d.HatAxes[other]
+	return x, y, paired && o.number == d.HatAxes[index].number
+}
+
+// put the events a hat axis changing, so its hat moving to x,y, causes, onto any registered channel(s).
+func (d HID) hatChanged(index uint8, t time.Duration, raw int16, x, y float32) {
+	number := d.HatAxes[index].number
+	layer := d.activeLayer()
+	xi, yi, paired := d.hatPair(index)
+	hx, hy := d.HatAxes[xi], d.HatAxes[yi]
+	src := func(k Kind, axis uint8, v float32) Source {
+		return Source{k, d.Index, number, axis, v, raw}
+	}
+	if x != hx.value {
+		hx.history = recorded(hx.history, hx.time, hx.value, t, x)
+	}
+	if y != hy.value {
+		hy.history = recorded(hy.history, hy.time, hy.value, t, y)
+	}
+	// events for each axis that has changed.
+	for _, a := range []struct {
+		hatAxis
+		v float32
+	}{{hx, x}, {hy, y}} {
+		if a.v == a.value {
+			continue
+		}
+		if c, _, ok := d.channel(HatChange, number, layer); ok {
+			c <- HatEvent{when{t}, src(HatChange, a.axis, a.v)}
+		}
+		switch a.axis {
+		case 1:
+			if c, _, ok := d.channel(HatPanY, number, layer); ok {
+				c <- AxisEvent{when{t}, src(HatPanY, a.axis, a.v), a.v}
 			}
-			d.Buttons[evt.Index] = button{b.number, toDuration(evt.Time), evt.Value != 0}
+			d.sendDerivatives(HatVelocityY, HatAccelerationY, layer, src(0, a.axis, a.v), t, a.history)
 		case 2:
-			h := d.HatAxes[evt.Index]
-			v := float32(evt.Value) / maxValue
-			if h.reversed {
-				v = -v
-			}
-			if c, ok := d.Events[eventSignature{hatChange, h.number}]; ok {
-				c <- HatEvent{when{toDuration(evt.Time)}, h.number, h.axis, v}
+			if c, _, ok := d.channel(HatPanX, number, layer); ok {
+				c <- AxisEvent{when{t}, src(HatPanX, a.axis, a.v), a.v}
 			}
-			switch h.axis {
-			case 1:
-				if c, ok := d.Events[eventSignature{hatPanY, h.number}]; ok {
-					c <- AxisEvent{when{toDuration(evt.Time)}, v}
-				}
-				if c, ok := d.Events[eventSignature{hatVelocityY, h.number}]; ok {
-					c <- AxisEvent{when{toDuration(evt.Time)}, (v-d.HatAxes[evt.Index].value)/float32((toDuration(evt.Time)-d.HatAxes[evt.Index].time).Seconds())}
-				}
-			case 2:
-				if c, ok := d.Events[eventSignature{hatPanX, h.number}]; ok {
-					c <- AxisEvent{when{toDuration(evt.Time)}, v}
-				}
-				if c, ok := d.Events[eventSignature{hatVelocityX, h.number}]; ok {
-					c <- AxisEvent{when{toDuration(evt.Time)}, (v-d.HatAxes[evt.Index].value)/float32((toDuration(evt.Time)-d.HatAxes[evt.Index].time).Seconds())}
-				}
-			}
-			if c, ok := d.Events[eventSignature{hatPosition, h.number}]; ok {
-				switch h.axis {
-				case 1:
-					c <- CoordsEvent{when{toDuration(evt.Time)},  v ,d.HatAxes[evt.Index+1].value}
-				case 2:
-					c <- CoordsEvent{when{toDuration(evt.Time)},  d.HatAxes[evt.Index-1].value,v}
-				}
-			}
-			if c, ok := d.Events[eventSignature{hatAngle, h.number}]; ok {
-				switch h.axis {
-				case 1:
-					c <- AngleEvent{when{toDuration(evt.Time)}, float32(math.Atan2(float64(d.HatAxes[evt.Index+1].value), float64(v)))}
-				case 2:
-					c <- AngleEvent{when{toDuration(evt.Time)}, float32(math.Atan2(float64(v), float64(d.HatAxes[evt.Index-1].value)))}
-				}
-			}
-			if c, ok := d.Events[eventSignature{hatRadius, h.number}]; ok {
-				switch h.axis {
-				case 1:
-					c <- RadiusEvent{when{toDuration(evt.Time)}, float32(math.Sqrt(float64(d.HatAxes[evt.Index+1].value)*float64(d.HatAxes[evt.Index+1].value) + float64(v)*float64(v)))}
-				case 2:
-					c <- RadiusEvent{when{toDuration(evt.Time)}, float32(math.Sqrt(float64(v)*float64(v) + float64(d.HatAxes[evt.Index-1].value)*float64(d.HatAxes[evt.Index-1].value)))}
-				}
-			}
-			if c, ok := d.Events[eventSignature{hatEdge, h.number}]; ok {
-				// fmt.Println(v,h)
-				if (v == 1 || v == -1) && h.value != 1 && h.value != -1 {
-					switch h.axis {
-					case 1:
-						c <- AngleEvent{when{toDuration(evt.Time)}, float32(math.Atan2(float64(d.HatAxes[evt.Index+1].value), float64(v)))}
-					case 2:
-						c <- AngleEvent{when{toDuration(evt.Time)}, float32(math.Atan2(float64(v), float64(d.HatAxes[evt.Index-1].value)))}
-					}
-				}
+			d.sendDerivatives(HatVelocityX, HatAccelerationX, layer, src(0, a.axis, a.v), t, a.history)
+		}
+	}
+	h := d.HatAxes[index]
+	v := x
+	if h.axis == 2 {
+		v = y
+	}
+	if c, _, ok := d.channel(HatPosition, number, layer); ok {
+		c <- CoordsEvent{when{t}, src(HatPosition, h.axis, v), x, y}
+	}
+	if c, _, ok := d.channel(HatAngle, number, layer); ok {
+		c <- AngleEvent{when{t}, src(HatAngle, h.axis, v), float32(math.Atan2(float64(y), float64(x)))}
+	}
+	if c, _, ok := d.channel(HatRadius, number, layer); ok {
+		c <- RadiusEvent{when{t}, src(HatRadius, h.axis, v), float32(math.Sqrt(float64(x)*float64(x) + float64(y)*float64(y)))}
+	}
+	if c, s, ok := d.channel(HatDirection, number, layer); ok {
+		sig := eventSignature{HatDirection, number, s.layer}
+		if dir := s.direction(d.directions[sig], x, y); dir != d.directions[sig] {
+			d.directions[sig] = dir
+			c <- DirectionEvent{when{t}, src(HatDirection, h.axis, v), dir}
+		}
+	}
+	if c, _, ok := d.channel(HatEdge, number, layer); ok {
+		if atEdge(x) && !atEdge(hx.value) || atEdge(y) && !atEdge(hy.value) {
+			c <- AngleEvent{when{t}, src(HatEdge, h.axis, v), float32(math.Atan2(float64(y), float64(x)))}
+		}
+	}
+	if c, _, ok := d.channel(HatCentered, number, layer); ok {
+		if x == 0 && y == 0 && (hx.value != 0 || hy.value != 0) {
+			c <- HatEvent{when{t}, src(HatCentered, h.axis, v)}
+		}
+	}
+	if x != hx.value {
+		hx.value, hx.time = x, t
+	}
+	if y != hy.value {
+		hy.value, hy.time = y, t
+	}
+	if paired || h.axis == 1 {
+		d.HatAxes[xi] = hx
+	}
+	if paired || h.axis == 2 {
+		d.HatAxes[yi] = hy
+	}
+	d.sequenceHat(number, x, y, t)
+	d.thresholdsPassed(number, t, x, y)
+}
+
+// at full deflection, allowing for rounding from scaling.
+func atEdge(v float32) bool {
+	return v > 1-1e-6 || v < -1+1e-6
+}
+
+// put the events a button changing causes onto any registered channel(s).
+func (d HID) buttonChanged(index uint8, t time.Duration, raw int16) {
+	b := d.Buttons[index]
+	closed := raw == 1
+	if closed {
+		b.layer = d.activeLayer()
+	}
+	d.dualRoleOthers(b.number, t, raw)
+	src := func(k Kind) ButtonEvent {
+		return ButtonEvent{when{t}, Source{k, d.Index, b.number, 0, float32(raw), raw}, closed}
+	}
+	if c, _, ok := d.channel(ButtonChange, b.number, b.layer); ok {
+		c <- src(ButtonChange)
+	}
+	if raw == 0 {
+		if c, _, ok := d.channel(ButtonOpen, b.number, b.layer); ok {
+			c <- src(ButtonOpen)
+		}
+		if c, s, ok := d.channel(ButtonLongPress, b.number, b.layer); ok {
+			if t > b.time+s.LongPress {
+				c <- src(ButtonLongPress)
 			}
-			if c, ok := d.Events[eventSignature{hatCentered, h.number}]; ok {
-				if v == 0 && h.value != 0 {
-					switch h.axis {
-					case 2:
-						if d.HatAxes[evt.Index-1].value == 0 {
-							c <- when{toDuration(evt.Time)}
-						}
-					case 1:
-						if d.HatAxes[evt.Index+1].value == 0 {
-							c <- when{toDuration(evt.Time)}
-						}
-					}
-				}
+		}
+	}
+	if raw == 1 {
+		if c, _, ok := d.channel(ButtonClose, b.number, b.layer); ok {
+			c <- src(ButtonClose)
+		}
+		if c, s, ok := d.channel(ButtonDoublePress, b.number, b.layer); ok {
+			if t < b.time+s.DoublePress {
+				c <- src(ButtonDoublePress)
 			}
-			d.HatAxes[evt.Index] = hatAxis{h.number, h.axis, h.reversed, toDuration(evt.Time), v}
-		default:
-			// log.Println("unknown input type. ",evt.Type & 0x7f)
 		}
 	}
+	b.time, b.value = t, raw != 0
+	if closed {
+		b.presses++
+		d.startHold(index, b)
+		d.sequenceButton(b.number, t)
+	}
+	d.Buttons[index] = b
+	d.buttonAxisInput(b.number, t, closed)
+	d.tapped(index, b)
+	d.toggled(index, b)
+	d.dualRoled(index, b)
 }
 
 // Type of register-able methods and the index they are called with. (Note: the event type is indicated by the method.)
 type Channel struct {
 	Number uint8
-	Method func(HID, uint8) chan Event
+	Method func(HID, uint8, ...Option) chan Event
 }
 
 // Capture is highlevel automation of the setup of event channels.
@@ -248,103 +447,116 @@ func Capture(registrees ...Channel) []chan Event {
 	return chans
 }
 
-
-// button changes event channel.
-func (d HID) OnButton(index uint8) chan Event {
+// make a channel, and register it, with its settings, for an event signature.
+func (d HID) register(k Kind, index uint8, opts []Option) chan Event {
 	c := make(chan Event)
-	d.Events[eventSignature{buttonChange, index}] = c
+	s := newSettings(d.Timing, append(append([]Option{}, d.opts...), opts...))
+	d.Events[eventSignature{k, index, s.layer}] = c
+	d.settings[eventSignature{k, index, s.layer}] = s
 	return c
 }
 
+// the registered channel, and its settings, for an event on a layer, or, if none, the base layer.
+func (d HID) channel(k Kind, index, layer uint8) (chan Event, settings, bool) {
+	sig := eventSignature{k, index, layer}
+	c, ok := d.Events[sig]
+	if !ok && layer != 0 {
+		sig.layer = 0
+		c, ok = d.Events[sig]
+	}
+	return c, d.settings[sig], ok
+}
+
+// With returns a HID, sharing everything with this one, that applies the options provided to all the events it registers.
+// (for methods that can't take options themselves, also a way to apply options to many registrations.)
+func (d HID) With(opts ...Option) HID {
+	d.opts = append(append([]Option{}, d.opts...), opts...)
+	return d
+}
+
+// button changes event channel.
+func (d HID) OnButton(index uint8, opts ...Option) chan Event {
+	return d.register(ButtonChange, index, opts)
+}
+
 // button goes open event channel.
-func (d HID) OnOpen(index uint8) chan Event {
-	c := make(chan Event)
-	d.Events[eventSignature{buttonOpen, index}] = c
-	return c
+func (d HID) OnOpen(index uint8, opts ...Option) chan Event {
+	return d.register(ButtonOpen, index, opts)
 }
 
 // button goes closed event channel.
-func (d HID) OnClose(index uint8) chan Event {
-	c := make(chan Event)
-	d.Events[eventSignature{buttonClose, index}] = c
-	return c
+func (d HID) OnClose(index uint8, opts ...Option) chan Event {
+	return d.register(ButtonClose, index, opts)
 }
 
-// button goes open and the previous event, closed, was more than LongPressDelay ago, event channel.
-func (d HID) OnLong(index uint8) chan Event {
-	c := make(chan Event)
-	d.Events[eventSignature{buttonLongPress, index}] = c
-	return c
+// button goes open and the previous event, closed, was more than the LongPress Timing ago, event channel.
+func (d HID) OnLong(index uint8, opts ...Option) chan Event {
+	return d.register(ButtonLongPress, index, opts)
 }
 
-// button goes closed and the previous event, open, was less than DoublePressDelay ago, event channel.
-func (d HID) OnDouble(index uint8) chan Event {
-	c := make(chan Event)
-	d.Events[eventSignature{buttonDoublePress, index}] = c
-	return c
+// button goes closed and the previous event, open, was less than the DoublePress Timing ago, event channel.
+func (d HID) OnDouble(index uint8, opts ...Option) chan Event {
+	return d.register(ButtonDoublePress, index, opts)
 }
 
 // hat moved event channel.
-func (d HID) OnHat(index uint8) chan Event {
-	c := make(chan Event)
-	d.Events[eventSignature{hatChange, index}] = c
-	return c
+func (d HID) OnHat(index uint8, opts ...Option) chan Event {
+	return d.register(HatChange, index, opts)
 }
 
 // hat position changed event channel.
-func (d HID) OnMove(index uint8) chan Event {
-	c := make(chan Event)
-	d.Events[eventSignature{hatPosition, index}] = c
-	return c
+func (d HID) OnMove(index uint8, opts ...Option) chan Event {
+	return d.register(HatPosition, index, opts)
 }
 
 // hat axis-X moved event channel.
-func (d HID) OnPanX(index uint8) chan Event {
-	c := make(chan Event)
-	d.Events[eventSignature{hatPanX, index}] = c
-	return c
+func (d HID) OnPanX(index uint8, opts ...Option) chan Event {
+	return d.register(HatPanX, index, opts)
 }
 
 // hat axis-Y moved event channel.
-func (d HID) OnPanY(index uint8) chan Event {
-	c := make(chan Event)
-	d.Events[eventSignature{hatPanY, index}] = c
-	return c
+func (d HID) OnPanY(index uint8, opts ...Option) chan Event {
+	return d.register(HatPanY, index, opts)
 }
 
-// hat axis-X speed changed event channel.
-func (d HID) OnSpeedX(index uint8) chan Event {
-	c := make(chan Event)
-	d.Events[eventSignature{hatVelocityX, index}] = c
-	return c
+// hat axis-X speed changed event channel, see DeriveOver, MinStep and SmoothDerivative.
+func (d HID) OnSpeedX(index uint8, opts ...Option) chan Event {
+	return d.register(HatVelocityX, index, opts)
 }
 
-// hat axis-Y speed changed event channel.
-func (d HID) OnSpeedY(index uint8) chan Event {
-	c := make(chan Event)
-	d.Events[eventSignature{hatVelocityY, index}] = c
-	return c
+// hat axis-Y speed changed event channel, see DeriveOver, MinStep and SmoothDerivative.
+func (d HID) OnSpeedY(index uint8, opts ...Option) chan Event {
+	return d.register(HatVelocityY, index, opts)
+}
+
+// hat axis-X acceleration changed event channel.
+func (d HID) OnAccelerationX(index uint8, opts ...Option) chan Event {
+	return d.register(HatAccelerationX, index, opts)
+}
+
+// hat axis-Y acceleration changed event channel.
+func (d HID) OnAccelerationY(index uint8, opts ...Option) chan Event {
+	return d.register(HatAccelerationY, index, opts)
 }
 
 // hat angle changed event channel.
-func (d HID) OnRotate(index uint8) chan Event {
-	c := make(chan Event)
-	d.Events[eventSignature{hatAngle, index}] = c
-	return c
+func (d HID) OnRotate(index uint8, opts ...Option) chan Event {
+	return d.register(HatAngle, index, opts)
+}
+
+// hat distance from centre changed event channel.
+func (d HID) OnRadius(index uint8, opts ...Option) chan Event {
+	return d.register(HatRadius, index, opts)
 }
 
 // hat moved event channel.
-func (d HID) OnCenter(index uint8) chan Event {
-	c := make(chan Event)
-	d.Events[eventSignature{hatCentered, index}] = c
-	return c
+func (d HID) OnCenter(index uint8, opts ...Option) chan Event {
+	return d.register(HatCentered, index, opts)
 }
 
 // hat moved to edge
-func (d HID) OnEdge(index uint8) chan Event {
-	c := make(chan Event)
-	d.Events[eventSignature{hatEdge, index}] = c
-	return c
+func (d HID) OnEdge(index uint8, opts ...Option) chan Event {
+	return d.register(HatEdge, index, opts)
 }
 
 // hat integrate
@@ -356,7 +568,6 @@ func (d HID) OnEdge(index uint8) chan Event {
 //	return c
 //}
 
-
 // see if Button exists.
 func (d HID) ButtonExists(index uint8) (ok bool) {
 	for _, v := range d.Buttons {
@@ -397,5 +608,3 @@ func (d HID) HatCoords(index uint8, coords []float32) {
 func (d HID) InsertSyntheticEvent(v int16, t uint8, i uint8) {
 	d.OSEvents <- osEventRecord{Value: v, Type: t, Index: i}
 }
-
-
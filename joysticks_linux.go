@@ -3,11 +3,16 @@
 package joysticks
 
 import (
+	"bytes"
 	"encoding/binary"
 	"io"
+	"io/ioutil"
 	"os"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
+	"unsafe"
 )
 
 // see; https://www.kernel.org/doc/Documentation/input/joystick-api.txt
@@ -38,23 +43,53 @@ func Connect(index int) (d *HID) {
 	if e != nil {
 		return nil
 	}
-	d = &HID{make(chan osEventRecord), make(map[uint8]button), make(map[uint8]hatAxis), make(map[eventSignature]chan Event)}
+	d = newHID(index)
+	d.Name = deviceName(r)
+	d.ID = deviceID(index, d.Name)
 	// start thread to read joystick events to the joystick.state osEvent channel
 	go eventPipe(r, d.OSEvents)
 	d.populate()
+	d.loadCalibration()
 	return d
 }
 
+// the name the driver has for a device, see JSIOCGNAME.
+func deviceName(f *os.File) string {
+	var name [128]byte
+	request := uintptr(2<<30 | len(name)<<16 | 'j'<<8 | 0x13)
+	if _, _, e := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), request, uintptr(unsafe.Pointer(&name[0]))); e != 0 {
+		return ""
+	}
+	if i := bytes.IndexByte(name[:], 0); i >= 0 {
+		return string(name[:i])
+	}
+	return string(name[:])
+}
+
+// tells identical models apart, using, from sysfs, their serial number, or, without one, their physical connection.
+func deviceID(index int, name string) string {
+	dir := "/sys/class/input/js" + strconv.Itoa(index-1) + "/device/"
+	for _, f := range []string{"uniq", "phys"} {
+		if b, err := ioutil.ReadFile(dir + f); err == nil {
+			if s := strings.TrimSpace(string(b)); s != "" {
+				return name + " " + s
+			}
+		}
+	}
+	return name
+}
+
 // fill in the joysticks available events from the synthetic events burst produced initially by the driver.
 func (d HID) populate() {
 	for buttonNumber, hatNumber, axisNumber := 1, 1, 1; ; {
 		evt := <-d.OSEvents
 		switch evt.Type {
 		case 0x81:
-			d.Buttons[evt.Index] = button{uint8(buttonNumber), toDuration(evt.Time), evt.Value != 0}
+			d.Buttons[evt.Index] = button{number: uint8(buttonNumber), time: toDuration(evt.Time), value: evt.Value != 0}
 			buttonNumber += 1
 		case 0x82:
-			d.HatAxes[evt.Index] = hatAxis{uint8(hatNumber), uint8(axisNumber), false, toDuration(evt.Time), float32(evt.Value) / maxValue}
+			v := float32(evt.Value) / maxValue
+			d.HatAxes[evt.Index] = hatAxis{number: uint8(hatNumber), axis: uint8(axisNumber), time: toDuration(evt.Time), value: v, input: v, raw: evt.Value}
 			axisNumber += 1
 			if axisNumber > 2 {
 				axisNumber = 1
@@ -65,7 +100,6 @@ func (d HID) populate() {
 			return
 		}
 	}
-	return
 }
 
 // pipe any readable events onto channel.
@@ -60,8 +60,7 @@ func TestHIDsAdvanced(t *testing.T) {
 	b2 := js1.OnClose(2)
 	b3 := js1.OnClose(3)
 	b4 := js1.OnClose(4)
-	DefaultRepeat=time.Second/10
-	b5r := Repeater(js1.OnClose(5),js1.OnOpen(5))
+	b5r := Autofire(js1.OnClose(5),js1.OnOpen(5),RepeatEvery(time.Second/10))
 	
 	quit := js1.OnOpen(10)
 	h3 :=  PositionFromVelocity(js1.OnMove(1))
@@ -77,11 +76,10 @@ func TestHIDsAdvanced(t *testing.T) {
 		case <-quit:
 			return
 		case <-b1:
-			DefaultRepeat=time.Second		
 			play(NewSound(NewTone(time.Second/440, 1), time.Second/3))
 		case <-b2:
 			play(NewSound(NewTone(time.Second/660, 1), time.Second/3))
-			b5r = Repeater(js1.OnClose(5),js1.OnOpen(5))
+			b5r = Autofire(js1.OnClose(5),js1.OnOpen(5),RepeatEvery(time.Second))
 		case <-b3:
 			play(NewSound(NewTone(time.Second/250, 1), time.Second/3))
 		case <-b4:
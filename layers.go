@@ -0,0 +1,46 @@
+package joysticks
+
+// a layer, and the button that shifts to it.
+type shiftLayer struct {
+	button uint8
+	active bool
+	settings
+}
+
+// Layer makes a button shift to a layer (1...), while it's held, so that events registered on that layer, using the OnLayer option, are sent instead of those on the base layer.
+// events not registered on the layer still go to the base layer.
+// with the ToggleLayer option, each close of the button toggles the layer instead, and with the Suppress option, the button's own events aren't sent.
+// when more than one layer is active, the highest numbered is used. buttons stay on the layer they closed on until they open.
+func (d HID) Layer(number, button uint8, opts ...Option) {
+	d.layers[number] = &shiftLayer{button, false, newSettings(d.Timing, append(append([]Option{}, d.opts...), opts...))}
+}
+
+// the highest numbered active layer.
+func (d HID) activeLayer() (n uint8) {
+	for i, l := range d.layers {
+		if l.active && i > n {
+			n = i
+		}
+	}
+	return
+}
+
+// update layers with a button change, returns if the button's own events are suppressed.
+func (d HID) layered(index uint8, raw int16) (suppressed bool) {
+	number := d.Buttons[index].number
+	for _, l := range d.layers {
+		if l.button != number {
+			continue
+		}
+		switch {
+		case l.toggleLayer:
+			if raw != 0 {
+				l.active = !l.active
+			}
+		default:
+			l.active = raw != 0
+		}
+		suppressed = suppressed || l.suppress
+	}
+	return
+}
@@ -0,0 +1,84 @@
+package joysticks
+
+import (
+	"math"
+)
+
+// Radial menu event type, Sector is -1 when nothing is highlighted.
+type SectorEvent struct {
+	when
+	Source
+	Sector int
+}
+
+// RadialMenu creates a chan on which you get SectorEvent's, from the CoordsEvent's (like from OnMove) on the moves chan, for a menu of sectors, the first starting at the offset angle (radians, as AngleEvent's), and following in the direction of increasing angle.
+// while the position is further from the centre than the ActivationRadius, events, of kind Highlighted, are sent when the sector it's in changes, (a sector needs to be left by more than the Hysteresis angle.)
+// the highlighted sector is selected, with an event of kind Selected, when the position returns to the centre, or when an event arrives on the confirm chan, (which can be nil), then, on returning to the centre, a Highlighted event for sector -1.
+// ButtonEvent's, on the confirm chan, that aren't closed are ignored. the returned chan is closed when the moves chan is.
+func RadialMenu(moves, confirm chan Event, sectors uint8, offset float32, opts ...Option) chan Event {
+	c := make(chan Event)
+	s := newSettings(defaultTiming(), opts)
+	width := 2 * math.Pi / float64(sectors)
+	// sector an angle is in, staying in the current one until past its edge by more than the hysteresis.
+	sector := func(current int, angle float64) int {
+		if current >= 0 {
+			centre := float64(offset) + (float64(current)+.5)*width
+			if math.Abs(math.Remainder(angle-centre, 2*math.Pi)) <= width/2+float64(s.hysteresis) {
+				return current
+			}
+		}
+		return int(math.Mod(math.Mod(angle-float64(offset), 2*math.Pi)+2*math.Pi, 2*math.Pi)/width) % int(sectors)
+	}
+	go func() {
+		defer close(c)
+		highlighted, selected := -1, false
+		var last Event // that changed the highlight
+		send := func(e Event, k Kind, sector int) {
+			src := origin(e)
+			src.kind = k
+			c <- SectorEvent{when{e.Moment()}, src, sector}
+		}
+		for {
+			select {
+			case e, ok := <-moves:
+				if !ok {
+					return
+				}
+				ce, ok := e.(CoordsEvent)
+				if !ok || sectors == 0 {
+					continue
+				}
+				if ce.X*ce.X+ce.Y*ce.Y <= s.radius*s.radius {
+					if highlighted < 0 {
+						continue
+					}
+					if !selected {
+						send(e, Selected, highlighted)
+					}
+					send(e, Highlighted, -1)
+					highlighted, selected = -1, false
+					continue
+				}
+				if n := sector(highlighted, math.Atan2(float64(ce.Y), float64(ce.X))); n != highlighted {
+					highlighted, selected, last = n, false, e
+					send(e, Highlighted, n)
+				}
+			case e, ok := <-confirm:
+				if !ok {
+					confirm = nil
+					continue
+				}
+				if be, ok := e.(ButtonEvent); ok && !be.Closed {
+					continue
+				}
+				if highlighted >= 0 && !selected {
+					selected = true
+					src := origin(last)
+					src.kind = Selected
+					c <- SectorEvent{when{e.Moment()}, src, highlighted}
+				}
+			}
+		}
+	}()
+	return c
+}
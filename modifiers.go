@@ -7,15 +7,20 @@ import (
 
 // TODO drag event
 // TODO move plus edge continue events (self generating)
-// TODO smoother from PID
-// TODO two pans to a coord
-// TODO coords to two pans
 // TODO 1-d integrator
 
 var DefaultRepeat = time.Second /4
 var VelocityRepeat =  time.Second / 10
 
 
+// the Source of an event, if it has one.
+func origin(e Event) Source {
+	if o, ok := e.(interface{ Origin() Source }); ok {
+		return o.Origin()
+	}
+	return Source{}
+}
+
 // duplicate event onto two chan's
 func Duplicator(c chan Event)(chan Event,chan Event){
 	c1 := make(chan Event)
@@ -32,70 +37,146 @@ func Duplicator(c chan Event)(chan Event,chan Event){
 }
 
 
-// creates a chan on which you get CoordsEvent's that are the time integration of the CoordsEvent's on the parameter chan. 
-func PositionFromVelocity(c chan Event) chan Event{
-	extra := make(chan Event)
-	var x,y,vx,vy float32
-	var startTime time.Time
-	var startMoment time.Duration
-	var m time.Duration
-	var lt time.Time
-	ticker:=time.NewTicker(VelocityRepeat)
-	// receiving chan processor
-	go func(){
-		e:= <-c
-		startTime=time.Now()
-		startMoment=e.Moment()
-		lm:=startMoment
-		for e:=range c{
-			if ce,ok:=e.(CoordsEvent);ok{
-				lt=time.Now()
-				m=e.Moment()
-				dt:=float32((m-lm).Seconds())
-				x+=vx*dt
-				y+=vy*dt
-				vx,vy=ce.X,ce.Y
-				lm=	m
+// CoordsFromPans creates a chan on which you get CoordsEvent's, of kind HatPosition, made from the latest AxisEvent's on the x and y chans, (like from pan chans of different hats or HIDs), each time either changes.
+// note; a HID's OnPanY is its axis 1, the X of its OnMove, and OnPanX its axis 2, the Y, so to match OnMove use CoordsFromPans(d.OnPanY(n), d.OnPanX(n)).
+// events have the newest Moment of the two latest AxisEvent's, and the Source of the one that caused them.
+// the returned chan is closed when both chans are closed.
+func CoordsFromPans(x, y chan Event) chan Event {
+	c := make(chan Event)
+	go func() {
+		defer close(c)
+		var vx, vy float32
+		var mx, my time.Duration
+		for x != nil || y != nil {
+			var e Event
+			var ok bool
+			select {
+			case e, ok = <-x:
+				if !ok {
+					x = nil
+					continue
+				}
+				ae, isAxis := e.(AxisEvent)
+				if !isAxis {
+					continue
+				}
+				vx, mx = ae.V, ae.Moment()
+			case e, ok = <-y:
+				if !ok {
+					y = nil
+					continue
+				}
+				ae, isAxis := e.(AxisEvent)
+				if !isAxis {
+					continue
+				}
+				vy, my = ae.V, ae.Moment()
+			}
+			m := mx
+			if my > m {
+				m = my
 			}
+			src := origin(e)
+			src.kind = HatPosition
+			c <- CoordsEvent{when{m}, src, vx, vy}
 		}
-		ticker.Stop()
 	}()
-	// output chan processor
-	go func(){
-		var lx,ly,nx,ny,dt float32
-		for t:=range ticker.C{
-			dt=float32(t.Sub(lt).Seconds())
-			nx,ny=x+dt*vx,y+dt*vy
-			if nx!=lx || ny!=ly {
-				extra <-CoordsEvent{when{startMoment+t.Sub(startTime)},nx,ny}	
-				lx,ly=nx,ny
+	return c
+}
+
+// PansFromCoords splits the CoordsEvent's on a chan into AxisEvent's on two chans, the X as kind HatPanX, axis 1, and the Y as kind HatPanY, axis 2, (labelled by coordinate, not as a HID labels its axes, see CoordsFromPans,) an event going onto a chan only when its value changes, (both chans need to be read.)
+// the returned chans are closed when the parameter chan is.
+func PansFromCoords(c chan Event) (x, y chan Event) {
+	x, y = make(chan Event), make(chan Event)
+	go func() {
+		defer close(x)
+		defer close(y)
+		var started bool
+		var lx, ly float32
+		for e := range c {
+			ce, ok := e.(CoordsEvent)
+			if !ok {
+				continue
+			}
+			src := origin(e)
+			if !started || ce.X != lx {
+				src.kind, src.Axis, src.Value = HatPanX, 1, ce.X
+				x <- AxisEvent{when{e.Moment()}, src, ce.X}
+			}
+			if !started || ce.Y != ly {
+				src.kind, src.Axis, src.Value = HatPanY, 2, ce.Y
+				y <- AxisEvent{when{e.Moment()}, src, ce.Y}
 			}
+			started, lx, ly = true, ce.X, ce.Y
 		}
 	}()
-
-	return extra
+	return
 }
 
+// creates a chan on which you get CoordsEvent's that are the time integration of the CoordsEvent's on the parameter chan.
+// the interval between events is the Velocity Timing.
+// Deprecated: use NewIntegrator, which can also be limited, wrapped, scaled, reset and set.
+func PositionFromVelocity(c chan Event, opts ...Option) chan Event {
+	return NewIntegrator(c, opts...).Events
+}
 
-// creates a channel that, after receiving any event on the first parameter chan, and until any event on second chan parameter, regularly receives 'when' events.
-// the repeat interval is DefaultRepeat, and is stored, so retriggering is not effected by changing DefaultRepeat.
-func Repeater(c1,c2 chan Event)(chan Event){
+// Autofire creates a channel that, after receiving any event on the start chan, and until any event on the stop chan, regularly receives ButtonEvent's, of kind Repeat, with the source of the starting event.
+// like keyboard auto-repeat, the first event is after the RepeatAfter option's delay, (default the Repeat Timing), then every Repeat Timing, which, with the Accelerate option, shortens each time.
+// stop events when not repeating are ignored. when either chan is closed, the returned chan is closed.
+func Autofire(start, stop chan Event, opts ...Option) chan Event {
 	c := make(chan Event)
-	go func(){
-		interval:=DefaultRepeat
-		var ticker *time.Ticker
+	s := newSettings(defaultTiming(), opts)
+	go func() {
+		defer close(c)
+		var tick <-chan time.Time
+		var src Source
+		var began time.Time
+		var moment, interval time.Duration
+		var accelerating bool // after the first repeat at the Repeat Timing
 		for {
-			e:= <-c1
-			go func(interval time.Duration, startTime time.Time){
-				ticker=time.NewTicker(interval)
-				for t:=range ticker.C{
-					c <- when{e.Moment()+t.Sub(startTime)}
+			select {
+			case e, ok := <-start:
+				if !ok {
+					return
 				}
-			}(interval, time.Now())
-			<-c2
-			ticker.Stop()
+				src, moment, began = origin(e), e.Moment(), s.clock.Now()
+				src.kind = Repeat
+				interval, accelerating = s.Repeat, false
+				if s.repeatDelay > 0 {
+					tick = s.clock.After(s.repeatDelay)
+				} else {
+					tick = s.clock.After(interval)
+				}
+			case _, ok := <-stop:
+				if !ok {
+					return
+				}
+				tick = nil
+			case t := <-tick:
+				select {
+				case c <- ButtonEvent{when{moment + t.Sub(began)}, src, true}:
+				case _, ok := <-stop:
+					if !ok {
+						return
+					}
+					tick = nil
+					continue
+				}
+				if accelerating && s.acceleration > 0 && s.acceleration < 1 {
+					interval = time.Duration(float32(interval) * s.acceleration)
+					if interval < s.fastest {
+						interval = s.fastest
+					}
+				}
+				accelerating = true
+				tick = s.clock.After(interval)
+			}
 		}
 	}()
 	return c
 }
 
+// Deprecated: use Autofire.
+func Repeater(c1, c2 chan Event, opts ...Option) chan Event {
+	return Autofire(c1, c2, opts...)
+}
@@ -0,0 +1,315 @@
+package joysticks
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+// clock that only moves when advanced, signalling each timer made.
+type fakeClock struct {
+	sync.Mutex
+	now    time.Time
+	timers []fakeTimer
+	made   chan time.Duration
+}
+
+type fakeTimer struct {
+	at time.Time
+	c  chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(0, 0), made: make(chan time.Duration, 10)}
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.Lock()
+	defer f.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) After(d time.Duration) <-chan time.Time {
+	f.Lock()
+	defer f.Unlock()
+	c := make(chan time.Time, 1)
+	f.timers = append(f.timers, fakeTimer{f.now.Add(d), c})
+	f.made <- d
+	return c
+}
+
+func (f *fakeClock) Advance(d time.Duration) {
+	f.Lock()
+	defer f.Unlock()
+	f.now = f.now.Add(d)
+	var waiting []fakeTimer
+	for _, t := range f.timers {
+		if t.at.After(f.now) {
+			waiting = append(waiting, t)
+			continue
+		}
+		t.c <- f.now
+	}
+	f.timers = waiting
+}
+
+func withClock(c clock) Option {
+	return func(s *settings) { s.clock = c }
+}
+
+func TestAutofire(t *testing.T) {
+	clock := newFakeClock()
+	start, stop := make(chan Event), make(chan Event)
+	fire := Autofire(start, stop, withClock(clock), RepeatEvery(time.Millisecond*100), RepeatAfter(time.Millisecond*300), Accelerate(.5, time.Millisecond*30))
+
+	// stopping before starting is ignored
+	stop <- ButtonEvent{}
+	start <- ButtonEvent{when{time.Second}, Source{kind: ButtonClose, Number: 5}, true}
+	var at time.Duration
+	for _, interval := range []time.Duration{300, 100, 50, 30, 30} {
+		if d := <-clock.made; d != interval*time.Millisecond {
+			t.Errorf("expected timer of %v, got %v", interval*time.Millisecond, d)
+		}
+		clock.Advance(interval * time.Millisecond)
+		at += interval * time.Millisecond
+		e := (<-fire).(ButtonEvent)
+		if e.Moment() != time.Second+at || e.Kind() != Repeat || e.Number != 5 {
+			t.Errorf("expected repeat of button 5 at %v, got %+v", time.Second+at, e)
+		}
+	}
+	<-clock.made
+	stop <- ButtonEvent{}
+	clock.Advance(time.Second)
+	expectNone(t, fire, time.Millisecond*20)
+
+	// restarts from the initial delay and rate
+	start <- ButtonEvent{}
+	if d := <-clock.made; d != time.Millisecond*300 {
+		t.Errorf("expected initial delay, got %v", d)
+	}
+	close(start)
+	if _, ok := <-fire; ok {
+		t.Error("expected closed")
+	}
+}
+
+func TestFilters(t *testing.T) {
+	steps := func(c chan Event) {
+		// noise, then a step, then steady, each 10ms apart.
+		for i, v := range []float32{0, .1, -.1, .1, -.1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1} {
+			c <- AxisEvent{when: when{time.Duration(i) * time.Millisecond * 10}, V: v}
+		}
+		c <- HatEvent{}
+		close(c)
+	}
+	for name, f := range map[string]func(chan Event) chan Event{
+		"EMA":     func(c chan Event) chan Event { return EMA(c, time.Millisecond*20) },
+		"OneEuro": func(c chan Event) chan Event { return OneEuro(c, 1, 1, 1) },
+		"Kalman":  func(c chan Event) chan Event { return Kalman(c, 1, .01) },
+	} {
+		in := make(chan Event)
+		out := f(in)
+		go steps(in)
+		var vs []float32
+		for e := range out {
+			if ae, ok := e.(AxisEvent); ok {
+				vs = append(vs, ae.V)
+			}
+		}
+		if len(vs) != 15 {
+			t.Fatalf("%s: expected 15 events, got %d", name, len(vs))
+		}
+		for _, v := range vs[1:5] {
+			if v > .1 || v < -.1 {
+				t.Errorf("%s: expected noise reduced, got %v", name, vs[:5])
+				break
+			}
+		}
+		if vs[5] >= 1 || vs[14] < .95 || vs[14] > 1 {
+			t.Errorf("%s: expected step followed, with lag, got %v", name, vs[5:])
+		}
+	}
+
+	// coords filtered by axis, same moments not dividing by zero.
+	in := make(chan Event)
+	out := OneEuro(in, 1, 1, 1, MinStep(time.Millisecond*10))
+	go func() {
+		in <- CoordsEvent{X: 0, Y: 1}
+		in <- CoordsEvent{X: 1, Y: 1}
+		close(in)
+	}()
+	<-out
+	if e := (<-out).(CoordsEvent); e.X <= 0 || e.X >= 1 || e.Y != 1 {
+		t.Errorf("expected X filtered, Y unchanged, got %+v", e)
+	}
+}
+
+func TestIntegrator(t *testing.T) {
+	clock := newFakeClock()
+	in := make(chan Event)
+	i := NewIntegrator(in, withClock(clock), VelocityEvery(time.Millisecond*100), LimitX(-1, 1), WrapY(0, 1), Gain(2))
+	near := func(a, b float32) bool { return a-b < .001 && b-a < .001 }
+	in <- CoordsEvent{when{time.Second}, Source{}, 1, .3}
+	for _, w := range []struct {
+		advance time.Duration
+		moment  time.Duration
+		x, y    float32
+	}{{100, 1100, .2, .06}, {1000, 2100, 1, .66}, {300, 2400, 1, .84}, {100, 2500, 1, .9}, {100, 2600, 1, .96}, {100, 2700, 1, .02}} {
+		<-clock.made
+		clock.Advance(w.advance * time.Millisecond)
+		e := (<-i.Events).(CoordsEvent)
+		if e.Kind() != Integrated || e.Moment() != w.moment*time.Millisecond || !near(e.X, w.x) || !near(e.Y, w.y) {
+			t.Errorf("expected %v,%v at %v, got %+v", w.x, w.y, w.moment*time.Millisecond, e)
+		}
+	}
+	in <- CoordsEvent{when{time.Second * 3}, Source{}, 0, 0}
+	i.Set(2, .5)
+	if x, y := i.Position(); x != 1 || y != .5 {
+		t.Errorf("expected set, limited, to 1,.5, got %v,%v", x, y)
+	}
+	i.Reset()
+	if x, y := i.Position(); x != 0 || y != 0 {
+		t.Errorf("expected reset, got %v,%v", x, y)
+	}
+	close(in)
+	for range i.Events {
+	}
+	i.Reset() // doesn't block when stopped
+}
+
+func TestRadialMenu(t *testing.T) {
+	moves, confirm := make(chan Event), make(chan Event)
+	menu := RadialMenu(moves, confirm, 4, -math.Pi/4, ActivationRadius(.5), Hysteresis(.1))
+	move := func(degrees float64, r float32) {
+		s, c := math.Sincos(degrees * math.Pi / 180)
+		moves <- CoordsEvent{when{time.Second}, Source{kind: HatPosition, Number: 2}, r * float32(c), r * float32(s)}
+	}
+	expect := func(k Kind, sector int) {
+		if e := (<-menu).(SectorEvent); e.Kind() != k || e.Sector != sector || e.Number != 2 {
+			t.Errorf("expected %v sector %d, got %+v", k, sector, e)
+		}
+	}
+	move(0, .3)
+	go move(10, .8)
+	expect(Highlighted, 0)
+	move(48, .8) // within hysteresis
+	go move(60, .8)
+	expect(Highlighted, 1)
+	go move(60, .2)
+	expect(Selected, 1)
+	expect(Highlighted, -1)
+
+	// confirmed, not selected again on returning to the centre.
+	go move(180, 1)
+	expect(Highlighted, 2)
+	go func() {
+		confirm <- ButtonEvent{Closed: false}
+		confirm <- ButtonEvent{Closed: true}
+	}()
+	expect(Selected, 2)
+	go move(0, 0)
+	expect(Highlighted, -1)
+	close(confirm)
+	close(moves)
+	if _, ok := <-menu; ok {
+		t.Error("expected closed")
+	}
+}
+
+func TestPans(t *testing.T) {
+	x, y := make(chan Event), make(chan Event)
+	coords := CoordsFromPans(x, y)
+	expect := func(cx, cy float32, m time.Duration, device int) {
+		if e := (<-coords).(CoordsEvent); e.X != cx || e.Y != cy || e.Moment() != m || e.Device != device || e.Kind() != HatPosition {
+			t.Errorf("expected %v,%v at %v from %d, got %+v", cx, cy, m, device, e)
+		}
+	}
+	go func() { x <- AxisEvent{when{time.Second}, Source{Device: 1}, .5} }()
+	expect(.5, 0, time.Second, 1)
+	go func() { y <- AxisEvent{when{time.Second * 2}, Source{Device: 2}, -.5} }()
+	expect(.5, -.5, time.Second*2, 2)
+	// older moment
+	go func() { x <- AxisEvent{when{time.Second}, Source{Device: 1}, 1} }()
+	expect(1, -.5, time.Second*2, 1)
+	close(x)
+	go func() { y <- AxisEvent{when{time.Second * 3}, Source{Device: 2}, 0} }()
+	expect(1, 0, time.Second*3, 2)
+	close(y)
+	if _, ok := <-coords; ok {
+		t.Error("expected closed")
+	}
+
+	c := make(chan Event)
+	px, py := PansFromCoords(c)
+	go func() {
+		c <- CoordsEvent{when{time.Second}, Source{Number: 3}, .5, 0}
+		c <- CoordsEvent{when{time.Second * 2}, Source{Number: 3}, .5, 1}
+		close(c)
+	}()
+	if e := (<-px).(AxisEvent); e.V != .5 || e.Kind() != HatPanX || e.Axis != 1 || e.Number != 3 {
+		t.Errorf("expected X .5, got %+v", e)
+	}
+	if e := (<-py).(AxisEvent); e.V != 0 || e.Kind() != HatPanY || e.Axis != 2 {
+		t.Errorf("expected Y 0, got %+v", e)
+	}
+	if e := (<-py).(AxisEvent); e.V != 1 || e.Moment() != time.Second*2 {
+		t.Errorf("expected Y 1, got %+v", e)
+	}
+	if _, ok := <-px; ok {
+		t.Error("expected X closed, without an unchanged event")
+	}
+}
+
+func TestRegions(t *testing.T) {
+	for _, c := range []struct {
+		Region
+		x, y, margin float32
+		in           bool
+	}{
+		{Rect{.5, .5, 1, 1}, .6, .6, 0, true},
+		{Rect{.5, .5, 1, 1}, .45, .6, 0, false},
+		{Rect{.5, .5, 1, 1}, .45, .6, .1, true},
+		{Circle{0, 0, .5}, .3, .3, 0, true},
+		{Circle{0, 0, .5}, .4, .4, 0, false},
+		{AnnularSector{0, 0, .5, 1, -math.Pi / 4, math.Pi / 4}, .8, 0, 0, true},
+		{AnnularSector{0, 0, .5, 1, -math.Pi / 4, math.Pi / 4}, .2, 0, 0, false},
+		{AnnularSector{0, 0, .5, 1, -math.Pi / 4, math.Pi / 4}, 0, .8, 0, false},
+		{AnnularSector{0, 0, .5, 1, 3 * math.Pi / 4, -3 * math.Pi / 4}, -.8, 0, 0, true}, // across ±π
+		{AnnularSector{0, 0, .5, 1, -math.Pi / 4, math.Pi / 4}, .6, .65, .1, true},
+		{AnnularSector{0, 0, .5, 1, 0, 2 * math.Pi}, .8, 0, 0, true}, // full ring
+		{AnnularSector{0, 0, .5, 1, -math.Pi, math.Pi}, -.6, -.6, 0, true},
+		{AnnularSector{0, 0, .5, 1, -math.Pi, math.Pi}, .2, 0, 0, false},
+		{Polygon{{0, 0}, {1, 0}, {0, 1}}, .2, .2, 0, true},
+		{Polygon{{0, 0}, {1, 0}, {0, 1}}, .6, .6, 0, false},
+		{Polygon{{0, 0}, {1, 0}, {0, 1}}, .6, .6, .2, true},
+	} {
+		if c.Contains(c.x, c.y, c.margin) != c.in {
+			t.Errorf("%+v at %v,%v margin %v, expected in %v", c.Region, c.x, c.y, c.margin, c.in)
+		}
+	}
+
+	moves := make(chan Event)
+	regions := Regions(moves, []Region{Rect{.5, -1, 1, 1}, Circle{1, 0, .3}}, BorderHysteresis(.1))
+	move := func(x, y float32) {
+		moves <- CoordsEvent{when{time.Second}, Source{kind: HatPosition, Number: 1}, x, y}
+	}
+	expect := func(k Kind, region int) {
+		if e := (<-regions).(RegionEvent); e.Kind() != k || e.Region != region || e.Number != 1 {
+			t.Errorf("expected %v region %d, got %+v", k, region, e)
+		}
+	}
+	go move(.6, 0)
+	expect(RegionEntered, 0)
+	move(.45, 0) // within hysteresis
+	move(.6, 0)
+	go move(.8, 0)
+	expect(RegionEntered, 1)
+	go move(0, 0)
+	expect(RegionExited, 0)
+	expect(RegionExited, 1)
+	close(moves)
+	if _, ok := <-regions; ok {
+		t.Error("expected closed")
+	}
+}
@@ -0,0 +1,239 @@
+package joysticks
+
+import (
+	"math"
+	"time"
+)
+
+// Timing holds the durations used to decide, or generate, time dependent events.
+type Timing struct {
+	LongPress   time.Duration // button held closed for more than this, for a long press.
+	DoublePress time.Duration // button re-closed within this, for a double press.
+	Repeat      time.Duration // interval between repeated events.
+	Velocity    time.Duration // interval between events integrated from a velocity.
+	Chord       time.Duration // buttons of a chord all closed within this.
+}
+
+// the Timing new HIDs and modifiers start with, from the package defaults, LongPressDelay, DoublePressDelay, DefaultRepeat and VelocityRepeat, with a Chord of 100ms.
+// they're copied when a HID is connected, or a modifier made, so changing them doesn't effect existing ones, but shouldn't be done while events are being routed.
+func defaultTiming() Timing {
+	return Timing{LongPressDelay, DoublePressDelay, DefaultRepeat, VelocityRepeat, time.Second / 10}
+}
+
+// settings of an event registration, or modifier.
+type settings struct {
+	Timing
+	holdProgress     time.Duration
+	suppressTaps     bool
+	suppress         bool
+	toggleOnHold     bool
+	repeatDelay      time.Duration
+	acceleration     float32
+	fastest          time.Duration
+	clock            clock
+	layer            uint8
+	toggleLayer      bool
+	holdOnOtherPress bool
+	permissiveHold   bool
+	window           time.Duration // derivatives fitted over
+	minStep          time.Duration // between derivative samples
+	smoothing        time.Duration // of derivatives
+	gain             float32
+	limits           [2]*limit // X and Y, of integration
+	ways             uint8
+	radius           float32
+	hysteresis       float32 // radians
+	rampUp, rampDown float32 // per second
+	rampStep         time.Duration
+	opposing         Opposing
+	border           float32 // hysteresis of regions
+}
+
+// Option changes a setting of a single event registration, or modifier, from the default.
+type Option func(*settings)
+
+// apply options to settings that start with the Timing provided, and defaults for the rest.
+func newSettings(t Timing, opts []Option) settings {
+	s := settings{
+		Timing:     t,
+		clock:      realClock{},
+		window:     time.Millisecond * 50,
+		minStep:    time.Millisecond * 5,
+		gain:       1,
+		ways:       8,
+		radius:     .5,
+		hysteresis: math.Pi / 36,
+		border:     .05,
+		rampStep:   time.Millisecond * 10,
+	}
+	for _, o := range opts {
+		o(&s)
+	}
+	return s
+}
+
+// button needs to be held closed for more than d for a long press.
+func LongPressAfter(d time.Duration) Option {
+	return func(s *settings) { s.LongPress = d }
+}
+
+// button needs to re-close within d for a double press.
+func DoublePressWithin(d time.Duration) Option {
+	return func(s *settings) { s.DoublePress = d }
+}
+
+// repeat events every d.
+func RepeatEvery(d time.Duration) Option {
+	return func(s *settings) { s.Repeat = d }
+}
+
+// integrate velocity into events every d.
+func VelocityEvery(d time.Duration) Option {
+	return func(s *settings) { s.Velocity = d }
+}
+
+// send hold progress events every d.
+func HoldProgressEvery(d time.Duration) Option {
+	return func(s *settings) { s.holdProgress = d }
+}
+
+// only send the event for a whole sequence of taps.
+func SuppressTaps() Option {
+	return func(s *settings) { s.suppressTaps = true }
+}
+
+// buttons of a chord need to all close within d.
+func ChordWithin(d time.Duration) Option {
+	return func(s *settings) { s.Chord = d }
+}
+
+// stop the events of the buttons, or hats, an event is made from, being sent themselves.
+func Suppress() Option {
+	return func(s *settings) { s.suppress = true }
+}
+
+// toggle when held for the LongPress Timing, rather than when tapped.
+func ToggleOnHold() Option {
+	return func(s *settings) { s.toggleOnHold = true }
+}
+
+// first repeat after d, rather than the Repeat Timing.
+func RepeatAfter(d time.Duration) Option {
+	return func(s *settings) { s.repeatDelay = d }
+}
+
+// shorten the interval, after each repeat, by the factor (0...1), until it reaches fastest.
+func Accelerate(factor float32, fastest time.Duration) Option {
+	return func(s *settings) { s.acceleration, s.fastest = factor, fastest }
+}
+
+// source of time for timers, so they can be faked.
+type clock interface {
+	Now() time.Time
+	After(time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// register on a layer, so events are only sent while it's active.
+func OnLayer(n uint8) Option {
+	return func(s *settings) { s.layer = n }
+}
+
+// a layer is toggled by its button, rather than active while held.
+func ToggleLayer() Option {
+	return func(s *settings) { s.toggleLayer = true }
+}
+
+// a dual-role button is held if another button closes while deciding.
+func HoldOnOtherPress() Option {
+	return func(s *settings) { s.holdOnOtherPress = true }
+}
+
+// a dual-role button is held if another button closes and opens while deciding.
+func PermissiveHold() Option {
+	return func(s *settings) { s.permissiveHold = true }
+}
+
+// fit velocity and acceleration over the values from the last d, (default 50ms.)
+func DeriveOver(d time.Duration) Option {
+	return func(s *settings) { s.window = d }
+}
+
+// treat values, for velocity, acceleration and filters, as at least d apart, (default 5ms.)
+func MinStep(d time.Duration) Option {
+	return func(s *settings) { s.minStep = d }
+}
+
+// smooth velocity or acceleration with an exponential moving average of time constant d.
+func SmoothDerivative(d time.Duration) Option {
+	return func(s *settings) { s.smoothing = d }
+}
+
+// scale velocities, being integrated, by g.
+func Gain(g float32) Option {
+	return func(s *settings) { s.gain = g }
+}
+
+// limit an integrated X to min...max.
+func LimitX(min, max float32) Option {
+	return func(s *settings) { s.limits[0] = &limit{min, max, false} }
+}
+
+// limit an integrated Y to min...max.
+func LimitY(min, max float32) Option {
+	return func(s *settings) { s.limits[1] = &limit{min, max, false} }
+}
+
+// wrap an integrated X, past max back to min, and visa versa.
+func WrapX(min, max float32) Option {
+	return func(s *settings) { s.limits[0] = &limit{min, max, true} }
+}
+
+// wrap an integrated Y, past max back to min, and visa versa.
+func WrapY(min, max float32) Option {
+	return func(s *settings) { s.limits[1] = &limit{min, max, true} }
+}
+
+// a hat needs to be further than r from the centre to have a direction, or be selecting, (default .5.)
+func ActivationRadius(r float32) Option {
+	return func(s *settings) { s.radius = r }
+}
+
+// a hat needs to move more than a (radians) past the edge of a direction's sector, to change direction, (default 5°.)
+func Hysteresis(a float32) Option {
+	return func(s *settings) { s.hysteresis = a }
+}
+
+// move a button axis away from the centre at r per second, rather than immediately.
+func RampUp(r float32) Option {
+	return func(s *settings) { s.rampUp = r }
+}
+
+// move a button axis toward the centre at r per second, rather than immediately.
+func RampDown(r float32) Option {
+	return func(s *settings) { s.rampDown = r }
+}
+
+// move a ramping button axis in steps d apart, (default 10ms.)
+func RampStep(d time.Duration) Option {
+	return func(s *settings) { s.rampStep = d }
+}
+
+// resolve both buttons of a button axis being closed with o.
+func OpposingPresses(o Opposing) Option {
+	return func(s *settings) { s.opposing = o }
+}
+
+// a position needs to be outside a region by more than d to have left it, (default .05.)
+func BorderHysteresis(d float32) Option {
+	return func(s *settings) { s.border = d }
+}
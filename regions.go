@@ -0,0 +1,131 @@
+package joysticks
+
+import (
+	"math"
+)
+
+// Region of hat positions.
+type Region interface {
+	// Contains returns if a position is within the region, when grown, all round, by margin (>=0).
+	Contains(x, y, margin float32) bool
+}
+
+// Rect is a Region between min and max X and Y.
+type Rect struct {
+	MinX, MinY, MaxX, MaxY float32
+}
+
+func (r Rect) Contains(x, y, margin float32) bool {
+	return x >= r.MinX-margin && x <= r.MaxX+margin && y >= r.MinY-margin && y <= r.MaxY+margin
+}
+
+// Circle is a Region within R of X,Y.
+type Circle struct {
+	X, Y, R float32
+}
+
+func (c Circle) Contains(x, y, margin float32) bool {
+	return math.Hypot(float64(x-c.X), float64(y-c.Y)) <= float64(c.R+margin)
+}
+
+// AnnularSector is a Region between the Inner and Outer distances from X,Y, and angles From and To, (radians, as AngleEvent's, To going in the direction of increasing angle from From, a whole turn or more being a full ring.)
+type AnnularSector struct {
+	X, Y         float32
+	Inner, Outer float32
+	From, To     float32
+}
+
+func (s AnnularSector) Contains(x, y, margin float32) bool {
+	dx, dy := float64(x-s.X), float64(y-s.Y)
+	r := math.Hypot(dx, dy)
+	if r < float64(s.Inner-margin) || r > float64(s.Outer+margin) {
+		return false
+	}
+	if r == 0 {
+		return true // only reached if inside the margin of the inner edge
+	}
+	// a whole turn, (allowing for float32 rounding of 2π,) would otherwise wrap to nothing.
+	if float64(s.To-s.From) >= 2*math.Pi-1e-6 {
+		return true
+	}
+	span := math.Mod(float64(s.To-s.From)+4*math.Pi, 2*math.Pi)
+	a := math.Mod(math.Atan2(dy, dx)-float64(s.From)+4*math.Pi, 2*math.Pi)
+	// margin, as an angle, at this radius.
+	m := float64(margin) / r
+	return a <= span+m || a >= 2*math.Pi-m
+}
+
+// Polygon is a Region within the closed shape made by joining its points, X,Y pairs, in order.
+type Polygon [][2]float32
+
+func (p Polygon) Contains(x, y, margin float32) bool {
+	inside := false
+	for i := range p {
+		a, b := p[i], p[(i+1)%len(p)]
+		if (a[1] > y) != (b[1] > y) && x < a[0]+(y-a[1])*(b[0]-a[0])/(b[1]-a[1]) {
+			inside = !inside
+		}
+		if margin > 0 && segmentDistance(x, y, a, b) <= margin {
+			return true
+		}
+	}
+	return inside
+}
+
+// distance of x,y from the line segment between a and b.
+func segmentDistance(x, y float32, a, b [2]float32) float32 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	var t float32
+	if l := dx*dx + dy*dy; l > 0 {
+		t = ((x-a[0])*dx + (y-a[1])*dy) / l
+		if t < 0 {
+			t = 0
+		} else if t > 1 {
+			t = 1
+		}
+	}
+	return float32(math.Hypot(float64(x-a[0]-t*dx), float64(y-a[1]-t*dy)))
+}
+
+// Region event type, Region is the index of the region entered or exited.
+type RegionEvent struct {
+	when
+	Source
+	Region int
+}
+
+// Regions creates a chan on which you get RegionEvent's, of kinds RegionEntered and RegionExited, as the positions of the CoordsEvent's on the moves chan, (like from OnMove), go into, and out of, the regions provided.
+// a position needs to be outside a region by more than the BorderHysteresis to have exited it, so jitter at its border doesn't cause events.
+// regions can overlap, exits are sent before enters, each in the order of the regions. the returned chan is closed when the moves chan is.
+func Regions(moves chan Event, regions []Region, opts ...Option) chan Event {
+	c := make(chan Event)
+	s := newSettings(defaultTiming(), opts)
+	go func() {
+		defer close(c)
+		in := make([]bool, len(regions))
+		send := func(e Event, k Kind, i int) {
+			src := origin(e)
+			src.kind = k
+			c <- RegionEvent{when{e.Moment()}, src, i}
+		}
+		for e := range moves {
+			ce, ok := e.(CoordsEvent)
+			if !ok {
+				continue
+			}
+			for i, r := range regions {
+				if in[i] && !r.Contains(ce.X, ce.Y, s.border) {
+					in[i] = false
+					send(e, RegionExited, i)
+				}
+			}
+			for i, r := range regions {
+				if !in[i] && r.Contains(ce.X, ce.Y, 0) {
+					in[i] = true
+					send(e, RegionEntered, i)
+				}
+			}
+		}
+	}()
+	return c
+}
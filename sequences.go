@@ -0,0 +1,135 @@
+package joysticks
+
+import (
+	"time"
+)
+
+// Sequence is a pattern of inputs, each Step needing to follow the previous one within the Within duration.
+// unless Strict, a diagonal step can be skipped, and hat directions next to the last one matched, or Neutral, are ignored.
+type Sequence struct {
+	Name   string
+	Steps  []Step
+	Within time.Duration
+	Strict bool
+}
+
+// Step of a Sequence, either a hat moving to a Direction, or, when Hat is zero, a button closing.
+type Step struct {
+	Hat       uint8
+	Direction Direction
+	Button    uint8
+}
+
+// Sequence matched event type, with the Moment of its first step as Start.
+type SequenceEvent struct {
+	when
+	Source
+	Sequence Sequence
+	Start    time.Duration
+}
+
+// state of the partial matches of some sequences.
+type recognizer struct {
+	sequences []Sequence
+	partials  []partial
+	hats      map[uint8]Direction
+	radius    float32 // hats are Neutral when closer to their centre
+	layer     uint8   // registered on, so only matched while it's active.
+}
+
+type partial struct {
+	sequence    int
+	next        int
+	start, last time.Duration
+	direction   Direction // of the last hat step matched
+}
+
+// sequences matched event channel.
+// receives a SequenceEvent, with the Sequence, each time any of them is matched, from the HID's button closes and hat directions.
+// more than one sequence can be being matched at once, and a sequence can start again before its previous attempt has failed.
+// the index of the sequence matched is the events Source.Number.
+// hats are quantised to 8 directions, Neutral when within the ActivationRadius, set, like other options, using With.
+// with OnLayer, matches are only sent while that layer is active.
+func (d HID) OnSequence(sequences ...Sequence) chan Event {
+	n := uint8(len(d.recognizers) + 1)
+	s := newSettings(d.Timing, d.opts)
+	d.recognizers[n] = &recognizer{sequences: sequences, hats: make(map[uint8]Direction), radius: s.radius, layer: s.layer}
+	return d.register(Sequenced, n, nil)
+}
+
+// feed a button close into any sequences.
+func (d HID) sequenceButton(number uint8, t time.Duration) {
+	for n, r := range d.recognizers {
+		d.sendSequences(n, r.input(Step{Button: number}, t), t)
+	}
+}
+
+// feed a hat position into any sequences, when its quantised direction changes.
+func (d HID) sequenceHat(number uint8, x, y float32, t time.Duration) {
+	for n, r := range d.recognizers {
+		dir := direction(x, y, 8, r.radius)
+		if r.hats[number] == dir {
+			continue
+		}
+		r.hats[number] = dir
+		d.sendSequences(n, r.input(Step{number, dir, 0}, t), t)
+	}
+}
+
+func (d HID) sendSequences(n uint8, matched []partial, t time.Duration) {
+	l := d.recognizers[n].layer
+	c, _, ok := d.channel(Sequenced, n, l)
+	if !ok || l != 0 && l != d.activeLayer() {
+		return
+	}
+	for _, p := range matched {
+		c <- SequenceEvent{when{t}, Source{Sequenced, d.Index, uint8(p.sequence), 0, 1, 1}, d.recognizers[n].sequences[p.sequence], p.start}
+	}
+}
+
+// advance partial matches with a step, returning any that are now complete, one for each sequence.
+func (r *recognizer) input(s Step, t time.Duration) (matched []partial) {
+	var kept []partial
+	done := make(map[int]bool)
+	for _, p := range r.partials {
+		q := r.sequences[p.sequence]
+		if done[p.sequence] || t-p.last > q.Within {
+			continue
+		}
+		want := q.Steps[p.next]
+		switch {
+		case want == s:
+			p.next++
+		case !q.Strict && want.Direction.diagonal() && p.next+1 < len(q.Steps) && q.Steps[p.next+1] == s:
+			p.next += 2
+		case !q.Strict && s.Button == 0 && (s.Direction == Neutral || s.Direction.adjacent(p.direction)):
+			kept = append(kept, p)
+			continue
+		default:
+			continue
+		}
+		p.last = t
+		if s.Button == 0 {
+			p.direction = s.Direction
+		}
+		if p.next < len(q.Steps) {
+			kept = append(kept, p)
+			continue
+		}
+		matched = append(matched, p)
+		done[p.sequence] = true
+	}
+	for i, q := range r.sequences {
+		if len(q.Steps) == 0 || q.Steps[0] != s || done[i] {
+			continue
+		}
+		p := partial{i, 1, t, t, s.Direction}
+		if len(q.Steps) == 1 {
+			matched = append(matched, p)
+			continue
+		}
+		kept = append(kept, p)
+	}
+	r.partials = kept
+	return
+}
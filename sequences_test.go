@@ -0,0 +1,88 @@
+package joysticks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSequence(t *testing.T) {
+	d := testHID(2, 1)
+	qcf := Sequence{"quarter circle forward punch", []Step{{1, S, 0}, {1, SE, 0}, {1, E, 0}, {Button: 1}}, time.Millisecond * 100, false}
+	kick := Sequence{"down kick", []Step{{1, S, 0}, {Button: 2}}, time.Millisecond * 100, true}
+	matched := d.OnSequence(qcf, kick)
+	go d.ParcelOutEvents()
+	input := func(ms uint32, typ, index uint8, v int16) {
+		d.OSEvents <- osEventRecord{ms, v, typ, index}
+	}
+	expect := func(name string, start, end time.Duration) {
+		e := (<-matched).(SequenceEvent)
+		if e.Sequence.Name != name || e.Start != start*time.Millisecond || e.Moment() != end*time.Millisecond {
+			t.Errorf("expected %q from %v to %v, got %+v", name, start*time.Millisecond, end*time.Millisecond, e)
+		}
+	}
+	// through all the directions
+	input(1000, 2, 1, maxValue)
+	input(1050, 2, 0, maxValue)
+	input(1100, 2, 1, 0)
+	input(1150, 1, 0, 1)
+	expect(qcf.Name, 1000, 1150)
+	input(1200, 1, 0, 0)
+	input(1200, 2, 0, 0)
+
+	// diagonal skipped, through Neutral
+	input(2000, 2, 1, maxValue)
+	input(2050, 2, 1, 0)
+	input(2100, 2, 0, maxValue)
+	input(2150, 1, 0, 1)
+	expect(qcf.Name, 2000, 2150)
+	input(2200, 1, 0, 0)
+	input(2200, 2, 0, 0)
+
+	// too slow
+	input(3000, 2, 1, maxValue)
+	input(3050, 2, 0, maxValue)
+	input(3100, 2, 1, 0)
+	input(3250, 1, 0, 1)
+	input(3300, 1, 0, 0)
+	input(3300, 2, 0, 0)
+
+	// strict, so fails on the Neutral.
+	input(4000, 2, 1, maxValue)
+	input(4010, 2, 1, 0)
+	input(4020, 1, 1, 1)
+	input(4030, 1, 1, 0)
+	input(4040, 2, 1, maxValue)
+	input(4050, 1, 1, 1)
+	expect(kick.Name, 4040, 4050)
+}
+
+func TestSequenceRadius(t *testing.T) {
+	d := testHID(1, 1)
+	kick := Sequence{"down kick", []Step{{1, S, 0}, {Button: 1}}, time.Millisecond * 100, true}
+	wide, narrow := d.With(ActivationRadius(.9)).OnSequence(kick), d.OnSequence(kick)
+	go d.ParcelOutEvents()
+	d.OSEvents <- osEventRecord{1000, reading(.7), 2, 1}
+	go func() { d.OSEvents <- osEventRecord{1010, 1, 1, 0} }()
+	<-narrow
+	expectNone(t, wide, time.Millisecond*20)
+}
+
+func TestLayeredSequence(t *testing.T) {
+	d := testHID(2, 1)
+	d.Layer(1, 2, Suppress())
+	kick := Sequence{"down kick", []Step{{1, S, 0}, {Button: 1}}, time.Millisecond * 100, true}
+	matched := d.With(OnLayer(1)).OnSequence(kick)
+	go d.ParcelOutEvents()
+	d.OSEvents <- osEventRecord{1000, maxValue, 2, 1}
+	d.OSEvents <- osEventRecord{1010, 1, 1, 0}
+	expectNone(t, matched, time.Millisecond*20)
+	d.OSEvents <- osEventRecord{1020, 0, 1, 0}
+	d.OSEvents <- osEventRecord{1030, 0, 2, 1}
+
+	d.OSEvents <- osEventRecord{2000, 1, 1, 1}
+	d.OSEvents <- osEventRecord{2010, maxValue, 2, 1}
+	go func() { d.OSEvents <- osEventRecord{2020, 1, 1, 0} }()
+	if e := (<-matched).(SequenceEvent); e.Sequence.Name != kick.Name {
+		t.Errorf("expected %q, on the layer, got %+v", kick.Name, e)
+	}
+}
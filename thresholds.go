@@ -0,0 +1,64 @@
+package joysticks
+
+import (
+	"math"
+	"time"
+)
+
+// a virtual button, closed by a hat axis passing a value.
+type threshold struct {
+	hat, axis  uint8
+	at         float32
+	hysteresis float32
+	closed     bool
+}
+
+// ThresholdButton adds a virtual button, with the number provided, that closes when an axis, (1 or 2), of a hat passes the value at, away from the centre, and opens when it comes back by more than the hysteresis.
+// an axis of 0 uses the hat's distance from its centre.
+// so, for example, an analog trigger can be used as a button with ThresholdButton(20, 3, 1, .6, .1), and stick-left with ThresholdButton(21, 1, 1, -.5, .1).
+// the button then works like any other, with all the On<xxx> button events, and ButtonClosed, (at index number-1.)
+// returns false, adding nothing, if the button already exists, or number is 0.
+func (d HID) ThresholdButton(number, hat, axis uint8, at, hysteresis float32) bool {
+	if number == 0 || d.ButtonExists(number) {
+		return false
+	}
+	if _, ok := d.Buttons[number-1]; ok {
+		return false
+	}
+	d.Buttons[number-1] = button{number: number}
+	d.thresholds[number-1] = &threshold{hat: hat, axis: axis, at: at, hysteresis: hysteresis}
+	return true
+}
+
+// input changes to the virtual buttons of a hat that has moved to x,y.
+func (d HID) thresholdsPassed(hat uint8, t time.Duration, x, y float32) {
+	for index, th := range d.thresholds {
+		if th.hat != hat {
+			continue
+		}
+		var v float32
+		switch th.axis {
+		case 0:
+			v = float32(math.Sqrt(float64(x)*float64(x) + float64(y)*float64(y)))
+		case 1:
+			v = x
+		case 2:
+			v = y
+		}
+		if th.at < 0 {
+			v = -v
+		}
+		at := th.at
+		if at < 0 {
+			at = -at
+		}
+		switch {
+		case !th.closed && v > at:
+			th.closed = true
+			d.buttonInput(index, t, 1)
+		case th.closed && v < at-th.hysteresis:
+			th.closed = false
+			d.buttonInput(index, t, 0)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package joysticks
+
+import (
+	"math"
+)
+
+// orientation of a hat.
+type hatTransform struct {
+	swap     bool
+	sin, cos float32
+	rotated  bool
+}
+
+// InvertAxis of a hat, (1 or 2), so its readings are negated, before any other processing, apart from calibration.
+// not thread safe, with ParcelOutEvents, so use before it runs.
+func (d HID) InvertAxis(hat, axis uint8, invert bool) {
+	for i, h := range d.HatAxes {
+		if h.number == hat && h.axis == axis {
+			h.reversed = invert
+			d.HatAxes[i] = h
+		}
+	}
+}
+
+// SwapHatAxes of a hat, so its X reads from its Y axis, and visa versa, before any deadzone or curve.
+// use before ParcelOutEvents runs, (not thread safe with it.)
+func (d HID) SwapHatAxes(hat uint8, swap bool) {
+	t := d.transforms[hat]
+	t.swap = swap
+	d.transforms[hat] = t
+}
+
+// RotateHat positions by an angle, radians, in the direction of increasing angle of AngleEvent's, before any deadzone or curve, after any swap.
+// (positions are limited to {-1...1}, so rotated corners are cut off.)
+// like the other transforms, use before ParcelOutEvents runs.
+func (d HID) RotateHat(hat uint8, angle float32) {
+	t := d.transforms[hat]
+	s, c := math.Sincos(float64(angle))
+	// so quarter turns, of a float32 angle, leave the other axis exactly zero.
+	if math.Abs(s) < 1e-6 {
+		s = 0
+	}
+	if math.Abs(c) < 1e-6 {
+		c = 0
+	}
+	t.sin, t.cos, t.rotated = float32(s), float32(c), angle != 0
+	d.transforms[hat] = t
+}
+
+// apply a hat's swap and rotation to its position.
+func (d HID) transformed(hat uint8, x, y float32) (float32, float32) {
+	t, ok := d.transforms[hat]
+	if !ok {
+		return x, y
+	}
+	if t.swap {
+		x, y = y, x
+	}
+	if t.rotated {
+		x, y = clamp(x*t.cos-y*t.sin), clamp(x*t.sin+y*t.cos)
+	}
+	return x, y
+}
+
+func clamp(v float32) float32 {
+	switch {
+	case v > 1:
+		return 1
+	case v < -1:
+		return -1
+	}
+	return v
+}